@@ -22,26 +22,37 @@ func main() {
 	server := mcp.NewServer(logger)
 
 	// Register placeholders so tools/list is populated even before k8s is ready
-	reg := server.Registry()
-	tools.RegisterCluster(reg, nil, logger)
-	tools.RegisterWorkloads(reg, nil)
-	tools.RegisterResources(reg, nil)
-	tools.RegisterSecrets(reg, nil)
+	tools.RegisterAll(server.Registry(), nil, logger)
 
 	// Defer k8s client setup until after MCP initialize response
 	server.OnInitialized(func(bg context.Context, srv *mcp.Server) {
-		kc, err := k8s.Load(bg, logger)
+		kc, err := k8s.LoadWithRetry(bg, logger)
 		if err != nil {
 			logger.Warn("k8s not initialized", slog.String("error", err.Error()))
 			return
 		}
-		reg := srv.Registry()
 		// Re-register concrete implementations over placeholders
-		tools.RegisterCluster(reg, kc, logger)
-		tools.RegisterWorkloads(reg, kc)
-		tools.RegisterResources(reg, kc)
-		tools.RegisterSecrets(reg, kc)
+		tools.RegisterAll(srv.Registry(), kc, logger)
+		tools.ApplyToolPermissions(bg, srv.Registry(), kc)
 		logger.Info("k8s tools registered")
+		_ = srv.NotifyToolsListChanged()
+
+		// Handlers read kc.Current() per call, so a reload takes effect on the next call
+		kc.WatchKubeconfig(bg, k8s.KubeconfigPollInterval(), func(err error) {
+			if err != nil {
+				logger.Warn("kubeconfig reload failed", slog.String("error", err.Error()))
+				_ = srv.LogMessage("warning", map[string]any{"message": "kubeconfig changed but reload failed", "error": err.Error()})
+				return
+			}
+			logger.Info("kubeconfig reloaded")
+			_ = srv.LogMessage("info", map[string]any{"message": "kubeconfig changed, kubernetes clients reloaded"})
+			// the identity may have changed, so recompute which tools it can use
+			if os.Getenv("MCP_K8S_TOOLS_RBAC") != "" {
+				tools.RegisterAll(srv.Registry(), kc, logger)
+				tools.ApplyToolPermissions(bg, srv.Registry(), kc)
+				_ = srv.NotifyToolsListChanged()
+			}
+		})
 	})
 
 	if err := server.Run(ctx, os.Stdin, os.Stdout); err != nil {
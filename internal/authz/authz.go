@@ -1,10 +1,16 @@
 package authz
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 func IsReadOnly() bool { return os.Getenv("MCP_K8S_READONLY") == "true" }
@@ -40,6 +46,11 @@ func IsNamespaceAllowed(ns string) bool {
 	return false
 }
 
+// AllowedNamespaces returns MCP_K8S_NAMESPACE_ALLOWLIST; empty means all.
+func AllowedNamespaces() []string {
+	return parseCSV(os.Getenv("MCP_K8S_NAMESPACE_ALLOWLIST"))
+}
+
 func IsKindAllowed(kind string) bool {
 	if kind == "" {
 		return true
@@ -76,6 +87,55 @@ func EnforceMutating(tool string, ns string, kind string) error {
 	return nil
 }
 
+// Preflight asks the API server (SelfSubjectAccessReview) whether the current
+// identity may perform the action, so a denial reads "you lack patch on
+// deployments.apps in namespace X" instead of a raw Forbidden error. It is
+// best-effort: if the review itself fails, the real request decides.
+// Set MCP_K8S_RBAC_PREFLIGHT=false to skip it.
+func Preflight(ctx context.Context, cs kubernetes.Interface, attrs authorizationv1.ResourceAttributes) error {
+	if os.Getenv("MCP_K8S_RBAC_PREFLIGHT") == "false" {
+		return nil
+	}
+	allowed, reason, err := CanI(ctx, cs, attrs)
+	if err != nil || allowed {
+		return nil
+	}
+	msg := "you lack " + attrs.Verb + " on " + DescribeResource(attrs)
+	if attrs.Namespace != "" {
+		msg += " in namespace " + attrs.Namespace
+	}
+	if reason != "" {
+		msg += " (" + reason + ")"
+	}
+	return &GuardError{Code: "RBAC_DENIED", Message: msg}
+}
+
+// CanI runs a SelfSubjectAccessReview for attrs.
+func CanI(ctx context.Context, cs kubernetes.Interface, attrs authorizationv1.ResourceAttributes) (allowed bool, reason string, err error) {
+	a := attrs
+	review := &authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &a}}
+	res, err := cs.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	if res.Status.EvaluationError != "" && !res.Status.Allowed && !res.Status.Denied {
+		return false, "", fmt.Errorf("access review failed: %s", res.Status.EvaluationError)
+	}
+	return res.Status.Allowed, res.Status.Reason, nil
+}
+
+// DescribeResource renders attrs kubectl-style, e.g. "deployments.apps" or "pods/exec".
+func DescribeResource(attrs authorizationv1.ResourceAttributes) string {
+	r := attrs.Resource
+	if attrs.Group != "" {
+		r += "." + attrs.Group
+	}
+	if attrs.Subresource != "" {
+		r += "/" + attrs.Subresource
+	}
+	return r
+}
+
 // Token bucket rate limiter per tool
 type tokenBucket struct {
 	capacity     int
@@ -0,0 +1,193 @@
+package authz
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Exec policy, configured through comma-separated environment variables:
+//
+//	MCP_K8S_EXEC_ALLOW       argv patterns that may run; when set, nothing else may
+//	MCP_K8S_EXEC_DENY        argv patterns that never run (checked first)
+//	MCP_K8S_EXEC_NAMESPACES  namespaces where exec is permitted (globs)
+//	MCP_K8S_EXEC_IMAGES      container images exec may target (globs)
+//
+// An argv pattern is a space-separated list of globs; the first names the
+// program and the rest its arguments. Arguments containing a "/" are cleaned
+// (path.Clean) before matching, so "/etc/./shadow" and "/etc/../etc/shadow"
+// are both seen as "/etc/shadow". In globs "*" matches any characters,
+// including "/".
+//
+// Allow patterns match the arguments in order; a trailing "*" matches any
+// remaining arguments, so "cat *" allows cat with any arguments while "env"
+// allows only bare env. Deny patterns are not positional: they match when
+// the program matches and each argument glob matches some argument, so "rm"
+// denies rm with any arguments, "sh -c" denies sh with -c anywhere, and
+// "* /etc/shadow" denies every command naming /etc/shadow.
+//
+// A program glob with a "/" must match the program path exactly. Without one
+// it names a program: in the allowlist that is a bare name resolved by the
+// image's PATH or a path in one of the system directories (/bin, /usr/bin,
+// /sbin, /usr/sbin), so "cat *" does not admit /tmp/x/cat; in the denylist
+// any path with that base name matches.
+//
+// With MCP_K8S_EXEC_ALLOW set, pods-exec is permitted in read-only mode: the
+// allowlist is what makes exec safe enough for diagnostics.
+
+// ExecImagesRestricted reports whether the policy needs the target
+// container's image.
+func ExecImagesRestricted() bool { return os.Getenv("MCP_K8S_EXEC_IMAGES") != "" }
+
+// systemBinDirs are the directories a program named by a bare allowlist
+// glob may be run from by path.
+var systemBinDirs = []string{"/bin", "/usr/bin", "/sbin", "/usr/sbin"}
+
+// EnforceExec applies the namespace and kind allowlists, read-only mode and
+// the exec policy to a command about to run in a container with the given
+// image.
+func EnforceExec(tool, ns, image string, argv []string) error {
+	allow := parseCSV(os.Getenv("MCP_K8S_EXEC_ALLOW"))
+	if IsReadOnly() && len(allow) == 0 {
+		return &GuardError{Code: "READ_ONLY_BLOCKED", Message: tool + " is blocked in read-only mode (set MCP_K8S_EXEC_ALLOW to permit specific commands)"}
+	}
+	if !IsNamespaceAllowed(ns) {
+		return &GuardError{Code: "NS_NOT_ALLOWED", Message: "Namespace " + ns + " is not in allowlist"}
+	}
+	if !IsKindAllowed("Pod") {
+		return &GuardError{Code: "KIND_NOT_ALLOWED", Message: "Kind Pod is not in allowlist"}
+	}
+	if namespaces := parseCSV(os.Getenv("MCP_K8S_EXEC_NAMESPACES")); len(namespaces) > 0 && !matchAnyGlob(namespaces, ns) {
+		return &GuardError{Code: "EXEC_NAMESPACE_NOT_ALLOWED", Message: "exec is not permitted in namespace " + ns + " (MCP_K8S_EXEC_NAMESPACES)"}
+	}
+	if images := parseCSV(os.Getenv("MCP_K8S_EXEC_IMAGES")); len(images) > 0 && !matchAnyGlob(images, image) {
+		return &GuardError{Code: "EXEC_IMAGE_NOT_ALLOWED", Message: "exec is not permitted in containers running image " + image + " (MCP_K8S_EXEC_IMAGES)"}
+	}
+	cmd := strings.Join(argv, " ")
+	for _, p := range parseCSV(os.Getenv("MCP_K8S_EXEC_DENY")) {
+		if matchDeny(p, argv) {
+			return &GuardError{Code: "EXEC_COMMAND_DENIED", Message: "command " + strconv.Quote(cmd) + " is denied by exec policy pattern " + strconv.Quote(p)}
+		}
+	}
+	if len(allow) == 0 {
+		return nil
+	}
+	for _, p := range allow {
+		if matchArgv(p, argv) {
+			return nil
+		}
+	}
+	return &GuardError{Code: "EXEC_COMMAND_NOT_ALLOWED", Message: "command " + strconv.Quote(cmd) + " is not in the exec allowlist (" + strings.Join(allow, ", ") + ")"}
+}
+
+// matchArgv matches argv against an allow pattern, argument by argument.
+func matchArgv(pattern string, argv []string) bool {
+	globs := strings.Fields(pattern)
+	if len(globs) == 0 || len(argv) == 0 || !matchProgram(globs[0], argv[0], true) {
+		return false
+	}
+	args := cleanArgs(argv[1:])
+	for i, g := range globs[1:] {
+		if g == "*" && i == len(globs)-2 {
+			return true
+		}
+		if i >= len(args) || !matchGlob(g, args[i]) {
+			return false
+		}
+	}
+	return len(args) == len(globs)-1
+}
+
+// matchDeny matches argv against a deny pattern: the program, then each
+// argument glob against any argument.
+func matchDeny(pattern string, argv []string) bool {
+	globs := strings.Fields(pattern)
+	if len(globs) == 0 || len(argv) == 0 || !matchProgram(globs[0], argv[0], false) {
+		return false
+	}
+	args := cleanArgs(argv[1:])
+	for _, g := range globs[1:] {
+		if !matchAnyArg(g, args) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchProgram matches the program glob against argv[0]. strict selects the
+// allowlist rules for a bare glob (see the package comment).
+func matchProgram(glob, program string, strict bool) bool {
+	prog := program
+	if strings.Contains(prog, "/") {
+		prog = path.Clean(prog)
+	}
+	if strings.Contains(glob, "/") {
+		return matchGlob(glob, prog)
+	}
+	if strict && strings.Contains(program, "/") && !inSystemBinDir(prog) {
+		return false
+	}
+	return matchGlob(glob, path.Base(prog))
+}
+
+func matchAnyArg(glob string, args []string) bool {
+	for _, a := range args {
+		if matchGlob(glob, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanArgs returns args with path-like arguments cleaned.
+func cleanArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if strings.Contains(a, "/") {
+			a = path.Clean(a)
+		}
+		out[i] = a
+	}
+	return out
+}
+
+func inSystemBinDir(program string) bool {
+	dir := path.Dir(program)
+	for _, d := range systemBinDirs {
+		if dir == d {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyGlob(globs []string, s string) bool {
+	for _, g := range globs {
+		if matchGlob(g, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches s against a pattern where "*" stands for any run of
+// characters; every other character matches itself.
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, mid := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, mid)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(mid):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
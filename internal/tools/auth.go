@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+func RegisterAuth(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "auth-can-i", Description: "Check whether the current identity may perform an action, or list its rules in a namespace", DirectResult: true, Handler: notReady})
+		return
+	}
+	// auth-can-i
+	reg.Register(mcp.Tool{
+		Name:         "auth-can-i",
+		Description:  "Check whether the current identity may perform an action, or list its rules in a namespace",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("auth-can-i", 10, 5)
+			k := k.Current()
+			var p struct {
+				Verb, Group, Resource, Subresource, Name, Namespace string
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			// without a verb, list everything the identity may do in the namespace
+			if p.Verb == "" {
+				ns := p.Namespace
+				if ns == "" {
+					ns = k.DefaultNamespace
+				}
+				review := &authorizationv1.SelfSubjectRulesReview{Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: ns}}
+				res, err := k.Clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+				if err != nil {
+					return nil, err
+				}
+				out := map[string]any{
+					"namespace":        ns,
+					"resourceRules":    res.Status.ResourceRules,
+					"nonResourceRules": res.Status.NonResourceRules,
+					"incomplete":       res.Status.Incomplete,
+				}
+				if res.Status.EvaluationError != "" {
+					out["evaluationError"] = res.Status.EvaluationError
+				}
+				return out, nil
+			}
+			if p.Resource == "" {
+				return nil, errors.New("resource is required when verb is set")
+			}
+			attrs := authorizationv1.ResourceAttributes{Verb: p.Verb, Group: p.Group, Resource: p.Resource, Subresource: p.Subresource, Name: p.Name, Namespace: p.Namespace}
+			allowed, reason, err := authz.CanI(ctx, k.Clientset, attrs)
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{"allowed": allowed, "verb": p.Verb, "resource": authz.DescribeResource(attrs), "namespace": p.Namespace}
+			if reason != "" {
+				out["reason"] = reason
+			}
+			return out, nil
+		}),
+	})
+}
+
+// toolPermission is the API access a tool needs regardless of its arguments.
+type toolPermission struct {
+	attrs         authorizationv1.ResourceAttributes
+	clusterScoped bool
+}
+
+// toolPermissions covers tools with fixed targets; tools acting on arbitrary
+// kinds (resources-*) are checked per call by preflight instead.
+var toolPermissions = map[string]toolPermission{
+	"events-list":        {attrs: authorizationv1.ResourceAttributes{Verb: "list", Resource: "events"}},
+	"ns-list-namespaces": {attrs: authorizationv1.ResourceAttributes{Verb: "list", Resource: "namespaces"}, clusterScoped: true},
+	"ns-describe":        {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "namespaces"}, clusterScoped: true},
+	"ns-create":          {attrs: authorizationv1.ResourceAttributes{Verb: "create", Resource: "namespaces"}, clusterScoped: true},
+	"ns-delete":          {attrs: authorizationv1.ResourceAttributes{Verb: "delete", Resource: "namespaces"}, clusterScoped: true},
+	"nodes-list":         {attrs: authorizationv1.ResourceAttributes{Verb: "list", Resource: "nodes"}, clusterScoped: true},
+	"nodes-get":          {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "nodes"}, clusterScoped: true},
+	"nodes-cordon":       {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Resource: "nodes"}, clusterScoped: true},
+	"nodes-uncordon":     {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Resource: "nodes"}, clusterScoped: true},
+	"nodes-drain":        {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Resource: "nodes"}, clusterScoped: true},
+	"pods-list-pods":     {attrs: authorizationv1.ResourceAttributes{Verb: "list", Resource: "pods"}},
+	"pods-get":           {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}},
+	"pods-why-pending":   {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}},
+	"pods-diagnose":      {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}},
+	"pods-logs":          {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods", Subresource: "log"}},
+	"logs-search":        {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods", Subresource: "log"}},
+	"pods-exec":          {attrs: authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "exec"}},
+	"pods-cp-from":       {attrs: authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "exec"}},
+	"pods-cp-to":         {attrs: authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "exec"}},
+	"portforward-start":  {attrs: authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "portforward"}},
+	"workloads-scale":    {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Group: "apps", Resource: "deployments", Subresource: "scale"}},
+	"rollout-restart":    {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Group: "apps", Resource: "deployments"}},
+	"rollout-status":     {attrs: authorizationv1.ResourceAttributes{Verb: "get", Group: "apps", Resource: "deployments"}},
+	"rollout-history":    {attrs: authorizationv1.ResourceAttributes{Verb: "list", Group: "apps", Resource: "replicasets"}},
+	"rollout-undo":       {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Group: "apps", Resource: "deployments"}},
+	"jobs-list":          {attrs: authorizationv1.ResourceAttributes{Verb: "list", Group: "batch", Resource: "jobs"}},
+	"jobs-failed-logs":   {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods", Subresource: "log"}},
+	"cronjobs-trigger":   {attrs: authorizationv1.ResourceAttributes{Verb: "create", Group: "batch", Resource: "jobs"}},
+	"cronjobs-suspend":   {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Group: "batch", Resource: "cronjobs"}},
+	"cronjobs-resume":    {attrs: authorizationv1.ResourceAttributes{Verb: "patch", Group: "batch", Resource: "cronjobs"}},
+	"cronjobs-history":   {attrs: authorizationv1.ResourceAttributes{Verb: "list", Group: "batch", Resource: "jobs"}},
+	"top-pods":           {attrs: authorizationv1.ResourceAttributes{Verb: "list", Group: "metrics.k8s.io", Resource: "pods"}},
+	"top-nodes":          {attrs: authorizationv1.ResourceAttributes{Verb: "list", Group: "metrics.k8s.io", Resource: "nodes"}, clusterScoped: true},
+	"secrets-get":        {attrs: authorizationv1.ResourceAttributes{Verb: "get", Resource: "secrets"}},
+	"secrets-set":        {attrs: authorizationv1.ResourceAttributes{Verb: "update", Resource: "secrets"}},
+}
+
+// ApplyToolPermissions checks toolPermissions for the current identity in
+// the allowed namespaces (MCP_K8S_NAMESPACE_ALLOWLIST, else the default
+// namespace). Depending on MCP_K8S_TOOLS_RBAC it appends a note to the
+// description of tools that can never succeed ("annotate") or unregisters
+// them ("hide"); any other value leaves the registry alone. It returns the
+// affected tool names. Call it again after re-registering tools.
+func ApplyToolPermissions(ctx context.Context, reg *mcp.Registry, k *k8s.Clients) []string {
+	mode := os.Getenv("MCP_K8S_TOOLS_RBAC")
+	if k == nil || (mode != "annotate" && mode != "hide") {
+		return nil
+	}
+	k = k.Current()
+	namespaces := authz.AllowedNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{k.DefaultNamespace}
+	}
+	var affected []string
+	for name, perm := range toolPermissions {
+		t, ok := reg.Get(name)
+		if !ok {
+			continue
+		}
+		scopes := namespaces
+		if perm.clusterScoped {
+			scopes = []string{""}
+		}
+		usable := false
+		for _, ns := range scopes {
+			attrs := perm.attrs
+			attrs.Namespace = ns
+			allowed, _, err := authz.CanI(ctx, k.Clientset, attrs)
+			// an unanswerable review never hides a tool
+			if err != nil || allowed {
+				usable = true
+				break
+			}
+		}
+		if usable {
+			continue
+		}
+		affected = append(affected, name)
+		if mode == "hide" {
+			reg.Remove(name)
+			continue
+		}
+		note := " [unavailable: you lack " + perm.attrs.Verb + " on " + authz.DescribeResource(perm.attrs)
+		if !perm.clusterScoped {
+			note += " in namespaces " + strings.Join(scopes, ",")
+		}
+		t.Description += note + "]"
+		reg.Register(t)
+	}
+	return affected
+}
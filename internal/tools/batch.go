@@ -0,0 +1,489 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// defaultCronJobHistory is how many Jobs cronjobs-history returns by default.
+const defaultCronJobHistory = 10
+
+// jobSummary is one Job with its outcome.
+type jobSummary struct {
+	Name           string     `json:"name"`
+	Namespace      string     `json:"namespace"`
+	CronJob        string     `json:"cronJob,omitempty"`
+	Status         string     `json:"status"` // Complete, Failed, Suspended, Running or Pending
+	Completions    string     `json:"completions"`
+	Active         int32      `json:"active"`
+	Succeeded      int32      `json:"succeeded"`
+	Failed         int32      `json:"failed"`
+	Reason         string     `json:"reason,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	Duration       string     `json:"duration,omitempty"`
+	created        time.Time
+}
+
+func summarizeJob(job *batchv1.Job) jobSummary {
+	s := jobSummary{
+		Name:      job.Name,
+		Namespace: job.Namespace,
+		Active:    job.Status.Active,
+		Succeeded: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+		Status:    "Pending",
+		created:   job.CreationTimestamp.Time,
+	}
+	if ref := metav1.GetControllerOf(job); ref != nil && ref.Kind == "CronJob" {
+		s.CronJob = ref.Name
+	}
+	desired := int32(1)
+	if job.Spec.Completions != nil {
+		desired = *job.Spec.Completions
+	}
+	s.Completions = fmt.Sprintf("%d/%d", job.Status.Succeeded, desired)
+	if job.Status.Active > 0 {
+		s.Status = "Running"
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete, batchv1.JobFailed, batchv1.JobSuspended:
+			s.Status = string(c.Type)
+			if c.Type == batchv1.JobFailed {
+				s.Reason, s.Message = c.Reason, c.Message
+			}
+		}
+	}
+	if t := job.Status.StartTime; t != nil {
+		s.StartTime = &t.Time
+		end := time.Now()
+		if c := job.Status.CompletionTime; c != nil {
+			s.CompletionTime = &c.Time
+			end = c.Time
+		}
+		s.Duration = end.Sub(t.Time).Round(time.Second).String()
+	}
+	return s
+}
+
+// sortJobsNewestFirst orders jobs by creation time, newest first.
+func sortJobsNewestFirst(jobs []jobSummary) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].created.Equal(jobs[j].created) {
+			return jobs[i].created.After(jobs[j].created)
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+}
+
+func RegisterBatch(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "jobs-list", Description: "List Jobs with their status and completion and failure counts", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "jobs-failed-logs", Description: "Find the failed pod of a Job and return its exit code, reason and logs", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "cronjobs-trigger", Description: "Run a CronJob now by creating a Job from its jobTemplate", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "cronjobs-suspend", Description: "Suspend a CronJob so it schedules no new Jobs", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "cronjobs-resume", Description: "Resume a suspended CronJob", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "cronjobs-history", Description: "Show a CronJob's schedule state and its recent Jobs with their outcome", DirectResult: true, Handler: notReady})
+		return
+	}
+	// jobs-list
+	reg.Register(mcp.Tool{
+		Name:         "jobs-list",
+		Description:  "List Jobs with their status and completion and failure counts",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("jobs-list", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace     string
+				AllNamespaces bool
+				LabelSelector string
+				CronJob       string // only Jobs created by this CronJob
+				Status        string // Complete, Failed, Suspended, Running or Pending
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			ns := p.Namespace
+			if p.AllNamespaces {
+				ns = ""
+			} else if ns == "" {
+				ns = k.DefaultNamespace
+			}
+			list, err := k.Clientset.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{LabelSelector: p.LabelSelector})
+			if err != nil {
+				return nil, err
+			}
+			jobs := []jobSummary{}
+			for i := range list.Items {
+				s := summarizeJob(&list.Items[i])
+				if p.CronJob != "" && s.CronJob != p.CronJob {
+					continue
+				}
+				if p.Status != "" && !strings.EqualFold(s.Status, p.Status) {
+					continue
+				}
+				jobs = append(jobs, s)
+			}
+			sortJobsNewestFirst(jobs)
+			return map[string]any{"jobs": jobs}, nil
+		}),
+	})
+
+	// jobs-failed-logs
+	reg.Register(mcp.Tool{
+		Name:         "jobs-failed-logs",
+		Description:  "Find the failed pod of a Job and return its exit code, reason and logs",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("jobs-failed-logs", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				logParams
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			job, err := k.Clientset.BatchV1().Jobs(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			sel, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+			if err != nil {
+				return nil, err
+			}
+			pods, err := k.Clientset.CoreV1().Pods(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+			if err != nil {
+				return nil, err
+			}
+			var failures []containerFailure
+			for i := range pods.Items {
+				if f, ok := failedContainer(&pods.Items[i]); ok {
+					failures = append(failures, f)
+				}
+			}
+			summary := summarizeJob(job)
+			if len(failures) == 0 {
+				if summary.Status == "Failed" {
+					return nil, fmt.Errorf("job %s failed (%s) but none of its pods is left to read logs from", p.Name, summary.Reason)
+				}
+				return nil, fmt.Errorf("job %s has no failed pods", p.Name)
+			}
+			// the most recent failure is the one that decided the outcome
+			sort.Slice(failures, func(i, j int) bool { return failures[i].finished.After(failures[j].finished) })
+			f := failures[0]
+			// a container restarted in place (restartPolicy OnFailure) keeps
+			// the failed run's log as the previous instance
+			p.Previous = f.previous
+			q, err := newLogQuery(p.logParams)
+			if err != nil {
+				return nil, err
+			}
+			// an evicted pod's log is usually gone; the reason still answers
+			cl, err := q.fetch(ctx, k, p.Namespace, f.Pod, f.Container)
+			if err != nil {
+				cl.Error = err.Error()
+			}
+			out := map[string]any{
+				"job":        p.Name,
+				"namespace":  p.Namespace,
+				"jobStatus":  summary.Status,
+				"failedPods": len(failures),
+				"pod":        f.Pod,
+				"container":  f.Container,
+				"exitCode":   f.ExitCode,
+				"reason":     f.Reason,
+				"previous":   f.previous,
+				"logs":       cl,
+			}
+			if f.Message != "" {
+				out["message"] = f.Message
+			}
+			if summary.Reason != "" {
+				out["jobReason"] = summary.Reason
+			}
+			return out, nil
+		}),
+	})
+
+	// cronjobs-trigger
+	reg.Register(mcp.Tool{
+		Name:         "cronjobs-trigger",
+		Description:  "Run a CronJob now by creating a Job from its jobTemplate",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("cronjobs-trigger", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				JobName         string // default <cronjob>-manual-<suffix>
+				DryRun          *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			if err := authz.EnforceMutating("cronjobs-trigger", p.Namespace, "Job"); err != nil {
+				return nil, err
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "create", Group: "batch", Resource: "jobs", Namespace: p.Namespace}); err != nil {
+				return nil, err
+			}
+			cj, err := k.Clientset.BatchV1().CronJobs(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			job := jobFromCronJob(cj, p.JobName)
+			dr := []string{}
+			if dryRun {
+				dr = []string{"All"}
+			}
+			// not retried: a create that timed out may still have run the job
+			created, err := k.Clientset.BatchV1().Jobs(p.Namespace).Create(ctx, job, metav1.CreateOptions{DryRun: dr})
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{"job": created.Name, "cronJob": cj.Name, "namespace": p.Namespace, "images": templateImages(&created.Spec.Template), "dryRun": dryRun}
+			if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+				out["note"] = "the CronJob is suspended; this manual run still goes ahead"
+			}
+			return out, nil
+		},
+	})
+
+	// cronjobs-suspend
+	reg.Register(mcp.Tool{
+		Name:         "cronjobs-suspend",
+		Description:  "Suspend a CronJob so it schedules no new Jobs",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("cronjobs-suspend", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				DryRun          *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			return setSuspend(ctx, k, "cronjobs-suspend", p.Namespace, p.Name, true, p.DryRun == nil || *p.DryRun)
+		},
+	})
+
+	// cronjobs-resume
+	reg.Register(mcp.Tool{
+		Name:         "cronjobs-resume",
+		Description:  "Resume a suspended CronJob",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("cronjobs-resume", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				DryRun          *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			return setSuspend(ctx, k, "cronjobs-resume", p.Namespace, p.Name, false, p.DryRun == nil || *p.DryRun)
+		},
+	})
+
+	// cronjobs-history
+	reg.Register(mcp.Tool{
+		Name:         "cronjobs-history",
+		Description:  "Show a CronJob's schedule state and its recent Jobs with their outcome",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("cronjobs-history", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				Limit           int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			if p.Limit <= 0 {
+				p.Limit = defaultCronJobHistory
+			}
+			cj, err := k.Clientset.BatchV1().CronJobs(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			list, err := k.Clientset.BatchV1().Jobs(p.Namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			jobs := []jobSummary{}
+			outcomes := map[string]int{}
+			for i := range list.Items {
+				if !metav1.IsControlledBy(&list.Items[i], cj) {
+					continue
+				}
+				s := summarizeJob(&list.Items[i])
+				outcomes[s.Status]++
+				jobs = append(jobs, s)
+			}
+			sortJobsNewestFirst(jobs)
+			if len(jobs) > p.Limit {
+				jobs = jobs[:p.Limit]
+			}
+			active := []string{}
+			for _, ref := range cj.Status.Active {
+				active = append(active, ref.Name)
+			}
+			out := map[string]any{
+				"cronJob":   cj.Name,
+				"namespace": p.Namespace,
+				"schedule":  cj.Spec.Schedule,
+				"suspend":   cj.Spec.Suspend != nil && *cj.Spec.Suspend,
+				"active":    active,
+				"outcomes":  outcomes,
+				"jobs":      jobs,
+			}
+			if cj.Spec.TimeZone != nil {
+				out["timeZone"] = *cj.Spec.TimeZone
+			}
+			if t := cj.Status.LastScheduleTime; t != nil {
+				out["lastScheduleTime"] = t.Time
+			}
+			if t := cj.Status.LastSuccessfulTime; t != nil {
+				out["lastSuccessfulTime"] = t.Time
+			}
+			return out, nil
+		}),
+	})
+}
+
+// jobFromCronJob builds a Job from a CronJob's template, owned by the
+// CronJob and annotated like kubectl create job --from=cronjob/....
+func jobFromCronJob(cj *batchv1.CronJob, name string) *batchv1.Job {
+	if name == "" {
+		suffix := "-manual-" + strconv.FormatInt(time.Now().Unix(), 36)
+		// job names end up in a label value, which is capped at 63 bytes
+		base := cj.Name
+		if len(base)+len(suffix) > 63 {
+			base = base[:63-len(suffix)]
+		}
+		name = base + suffix
+	}
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for k, v := range cj.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       cj.Namespace,
+			Labels:          cj.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cj, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: cj.Spec.JobTemplate.Spec,
+	}
+}
+
+// setSuspend suspends or resumes a CronJob through the authz guards.
+// Running Jobs are not affected either way.
+func setSuspend(ctx context.Context, k *k8s.Clients, tool, namespace, name string, suspend, dryRun bool) (any, error) {
+	if err := authz.EnforceMutating(tool, namespace, "CronJob"); err != nil {
+		return nil, err
+	}
+	if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "patch", Group: "batch", Resource: "cronjobs", Name: name, Namespace: namespace}); err != nil {
+		return nil, err
+	}
+	dr := []string{}
+	if dryRun {
+		dr = []string{"All"}
+	}
+	patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+	var cj *batchv1.CronJob
+	// setting a fixed value is idempotent
+	err := k8s.Retry(ctx, func() error {
+		var err error
+		cj, err = k.Clientset.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{DryRun: dr})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"cronJob": cj.Name, "namespace": namespace, "suspend": cj.Spec.Suspend != nil && *cj.Spec.Suspend, "activeJobs": len(cj.Status.Active), "dryRun": dryRun}, nil
+}
+
+// containerFailure is a container that exited non-zero.
+type containerFailure struct {
+	Pod       string
+	Container string
+	ExitCode  int32
+	Reason    string
+	Message   string
+	previous  bool // the failure is the container's previous instance
+	finished  time.Time
+}
+
+// failedContainer finds the container of pod that exited non-zero, init
+// containers first, looking at the last termination of restarted ones too.
+func failedContainer(pod *corev1.Pod) (containerFailure, bool) {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		for _, st := range []struct {
+			t        *corev1.ContainerStateTerminated
+			previous bool
+		}{{cs.State.Terminated, false}, {cs.LastTerminationState.Terminated, true}} {
+			if st.t == nil || st.t.ExitCode == 0 {
+				continue
+			}
+			finished := st.t.FinishedAt.Time
+			if finished.IsZero() {
+				finished = pod.CreationTimestamp.Time
+			}
+			return containerFailure{Pod: pod.Name, Container: cs.Name, ExitCode: st.t.ExitCode, Reason: st.t.Reason, Message: st.t.Message, previous: st.previous, finished: finished}, true
+		}
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		// evicted or deadline-exceeded pods fail without a container exit code
+		return containerFailure{Pod: pod.Name, Container: defaultContainer(pod), Reason: pod.Status.Reason, Message: pod.Status.Message, finished: pod.CreationTimestamp.Time}, true
+	}
+	return containerFailure{}, false
+}
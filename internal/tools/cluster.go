@@ -22,14 +22,19 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 		}
 		reg.Register(mcp.Tool{
 			Name:         "cluster-health",
-			Description:  "Get basic cluster health and version",
+			Description:  "Assess cluster health: API readiness and liveness, nodes, control plane, CoreDNS, latency and certificate expiry",
 			DirectResult: true, // Return result directly instead of MCP content wrapper
 			Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
 				_ = authz.RateLimit("cluster-health", 10, 5)
 				return map[string]any{
-					"status":         "healthy",
+					"status":         "unknown",
 					"clusterVersion": "unknown",
 					"timestamp":      time.Now().UTC().Format(time.RFC3339),
+					"findings": []healthFinding{{
+						Severity: "critical",
+						Check:    "client",
+						Message:  "Kubernetes client not initialized yet; no cluster checks were run",
+					}},
 				}, nil
 			},
 		})
@@ -43,19 +48,15 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 	// cluster-health
 	reg.Register(mcp.Tool{
 		Name:         "cluster-health",
-		Description:  "Get basic cluster health and version",
+		Description:  "Assess cluster health: API readiness and liveness, nodes, control plane, CoreDNS, latency and certificate expiry",
 		DirectResult: true, // Return result directly instead of MCP content wrapper
-		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, _ json.RawMessage) (any, error) {
 			if err := authz.RateLimit("cluster-health", 10, 5); err != nil {
 				return nil, err
 			}
-			ver := "unknown"
-			if v, err := k.Discovery.ServerVersion(); err == nil && v != nil && v.GitVersion != "" {
-				ver = v.GitVersion
-			}
-			out := map[string]any{"status": "healthy", "clusterVersion": ver, "timestamp": time.Now().UTC().Format(time.RFC3339)}
-			return out, nil
-		},
+			k := k.Current()
+			return assessClusterHealth(ctx, k), nil
+		}),
 	})
 
 	// cluster-list-contexts
@@ -65,6 +66,7 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 		DirectResult: true,
 		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
 			_ = authz.RateLimit("cluster-list-contexts", 10, 5)
+			k := k.Current()
 			_, items, err := k.ListContexts()
 			if err != nil {
 				return nil, err
@@ -84,6 +86,7 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 		DirectResult: true,
 		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("cluster-set-context", 5, 2)
+			k := k.Current()
 			var p struct {
 				Context string `json:"context"`
 			}
@@ -102,8 +105,9 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 		Name:         "ns-list-namespaces",
 		Description:  "List namespaces",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("ns-list-namespaces", 10, 5)
+			k := k.Current()
 			var p struct {
 				Limit *int `json:"limit,omitempty"`
 			}
@@ -124,6 +128,6 @@ func RegisterCluster(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
 				rows = rows[:*p.Limit]
 			}
 			return map[string]any{"namespaces": rows}, nil
-		},
+		}),
 	})
 }
@@ -0,0 +1,373 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+const (
+	maxInlineText  = 256 << 10 // text files up to this size are returned as text
+	maxInlineTotal = 4 << 20   // content returned inline per call
+	maxCopyScan    = 64 << 20  // archive read without save before giving up
+	maxCopySaved   = 2 << 30   // written to disk with save
+	maxCopyUpload  = 16 << 20
+)
+
+// errCopyStopped aborts the tar stream once enough has been read.
+var errCopyStopped = errors.New("copy stopped early")
+
+// copiedFile is one file read from a container. Content is inline text or
+// base64; with save it is written locally and URI points at it.
+type copiedFile struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Mode     string `json:"mode"`
+	Type     string `json:"type,omitempty"`   // symlink; omitted for regular files
+	Target   string `json:"target,omitempty"` // symlink target
+	Encoding string `json:"encoding,omitempty"`
+	Content  string `json:"content,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Omitted  string `json:"omitted,omitempty"` // why there is no content
+}
+
+// copyDir is where pods-cp-from saves files: MCP_K8S_CP_DIR, else
+// mcp-k8s-cp in the user's cache directory.
+func copyDir() (string, error) {
+	if d := os.Getenv("MCP_K8S_CP_DIR"); d != "" {
+		return d, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory to save into (set MCP_K8S_CP_DIR): %w", err)
+	}
+	return filepath.Join(cache, "mcp-k8s-cp"), nil
+}
+
+// newSaveDir creates a fresh directory for one copy under copyDir. The base
+// must be a real directory that other users cannot write to, so nobody else
+// can plant files or symlinks where the copy lands.
+func newSaveDir(namespace, pod string) (string, error) {
+	base, err := copyDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(base, 0o700); err != nil {
+		return "", err
+	}
+	fi, err := os.Lstat(base)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("save directory %s is not a directory (or is a symlink)", base)
+	}
+	if fi.Mode().Perm()&0o022 != 0 {
+		return "", fmt.Errorf("save directory %s is writable by other users", base)
+	}
+	return os.MkdirTemp(base, namespace+"-"+pod+"-")
+}
+
+func RegisterCopy(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "pods-cp-from", Description: "Copy a file or directory out of a container (tar over exec); small files inline, large ones saved locally", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "pods-cp-to", Description: "Copy a file into a container (tar over exec); dry-run by default", DirectResult: true, Handler: notReady})
+		return
+	}
+	// pods-cp-from
+	reg.Register(mcp.Tool{
+		Name:         "pods-cp-from",
+		Description:  "Copy a file or directory out of a container (tar over exec); small files inline, large ones saved locally",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("pods-cp-from", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Name, Container, Path string
+				Save                             bool // write files under MCP_K8S_CP_DIR instead of returning them
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dir, base, err := splitCopyPath(p.Path)
+			if err != nil {
+				return nil, err
+			}
+			r := execRequest{Namespace: p.Namespace, Pod: p.Name, Container: p.Container, Command: []string{"tar", "cf", "-", "-C", dir, base}, Timeout: maxExecTimeout}
+			if err := enforceExec(ctx, k, "pods-cp-from", &r); err != nil {
+				return nil, err
+			}
+			saveDir := ""
+			if p.Save {
+				if saveDir, err = newSaveDir(p.Namespace, p.Name); err != nil {
+					return nil, err
+				}
+			}
+			pr, pw := io.Pipe()
+			r.Stdout = pw
+			type outcome struct {
+				res *execResult
+				err error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				res, err := execInPod(ctx, k, r)
+				pw.CloseWithError(err)
+				done <- outcome{res, err}
+			}()
+			files, truncated, readErr := readTar(pr, dir, saveDir)
+			// unblock the exec if the archive was not read to the end
+			pr.CloseWithError(errCopyStopped)
+			o := <-done
+			// a failed exec surfaces as a read error too; report the cause
+			switch {
+			case truncated:
+			case o.err != nil && (readErr == nil || errors.Is(readErr, o.err)):
+				return nil, o.err
+			case o.err == nil && o.res.TimedOut:
+				return nil, fmt.Errorf("copy timed out after %s", maxExecTimeout)
+			case o.err == nil && o.res.ExitCode != 0:
+				return nil, tarError(o.res)
+			case readErr != nil:
+				return nil, readErr
+			}
+			out := map[string]any{"pod": p.Name, "path": p.Path, "files": files, "truncated": truncated}
+			if r.Container != "" {
+				out["container"] = r.Container
+			}
+			if saveDir != "" {
+				out["savedTo"] = saveDir
+			}
+			if truncated {
+				out["warning"] = fmt.Sprintf("stopped after reading %d MiB; set save=true to copy everything", maxCopyScan>>20)
+			}
+			return out, nil
+		},
+	})
+
+	// pods-cp-to
+	reg.Register(mcp.Tool{
+		Name:         "pods-cp-to",
+		Description:  "Copy a file into a container (tar over exec); dry-run by default",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("pods-cp-to", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Name, Container, Path string
+				Content                          string
+				Encoding                         string // text (default) or base64
+				Mode                             string // octal, default 0644
+				DryRun                           *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			dir, base, err := splitCopyPath(p.Path)
+			if err != nil {
+				return nil, err
+			}
+			data := []byte(p.Content)
+			switch p.Encoding {
+			case "", "text":
+			case "base64":
+				if data, err = base64.StdEncoding.DecodeString(p.Content); err != nil {
+					return nil, fmt.Errorf("invalid base64 content: %w", err)
+				}
+			default:
+				return nil, fmt.Errorf("invalid encoding %q (use text or base64)", p.Encoding)
+			}
+			if len(data) > maxCopyUpload {
+				return nil, fmt.Errorf("content is %d bytes; uploads are limited to %d", len(data), maxCopyUpload)
+			}
+			mode := int64(0o644)
+			if p.Mode != "" {
+				m, err := strconv.ParseUint(p.Mode, 8, 32)
+				if err != nil || m > 0o7777 {
+					return nil, fmt.Errorf("invalid mode %q (octal, e.g. 0644)", p.Mode)
+				}
+				mode = int64(m)
+			}
+			if err := authz.EnforceMutating("pods-cp-to", p.Namespace, "Pod"); err != nil {
+				return nil, err
+			}
+			r := execRequest{Namespace: p.Namespace, Pod: p.Name, Container: p.Container, Command: []string{"tar", "-xmf", "-", "-C", dir}, Timeout: maxExecTimeout}
+			if err := enforceExec(ctx, k, "pods-cp-to", &r); err != nil {
+				return nil, err
+			}
+			target := path.Join(dir, base)
+			if dryRun {
+				pod, err := k.Clientset.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+				if err != nil {
+					return nil, err
+				}
+				container := r.Container
+				if container == "" {
+					container = defaultContainer(pod)
+				}
+				if containerImage(pod, container) == "" {
+					return nil, fmt.Errorf("pod %s has no container %q", p.Name, container)
+				}
+				return map[string]any{"dryRun": true, "pod": p.Name, "container": container, "path": target, "bytes": len(data), "mode": fmt.Sprintf("%04o", mode)}, nil
+			}
+			var archive bytes.Buffer
+			tw := tar.NewWriter(&archive)
+			hdr := &tar.Header{Name: base, Mode: mode, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return nil, err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return nil, err
+			}
+			if err := tw.Close(); err != nil {
+				return nil, err
+			}
+			r.Stdin = &archive
+			res, err := execInPod(ctx, k, r)
+			if err != nil {
+				return nil, err
+			}
+			if res.TimedOut {
+				return nil, fmt.Errorf("copy timed out after %s", maxExecTimeout)
+			}
+			if res.ExitCode != 0 {
+				return nil, tarError(res)
+			}
+			return map[string]any{"pod": p.Name, "path": target, "bytes": len(data), "mode": fmt.Sprintf("%04o", mode)}, nil
+		},
+	})
+}
+
+// splitCopyPath splits a container path into the directory tar runs in and
+// the entry it archives or extracts.
+func splitCopyPath(p string) (dir, base string, err error) {
+	if p == "" {
+		return "", "", errors.New("path is required")
+	}
+	p = path.Clean(p)
+	dir, base = path.Dir(p), path.Base(p)
+	if base == "/" || base == "." || base == ".." {
+		return "", "", fmt.Errorf("invalid path %q: name a file or directory", p)
+	}
+	return dir, base, nil
+}
+
+func tarError(res *execResult) error {
+	msg := strings.TrimSpace(res.Stderr)
+	if res.ExitCode == 126 || res.ExitCode == 127 || strings.Contains(msg, "executable file not found") {
+		return fmt.Errorf("copying requires tar in the container: %s", msg)
+	}
+	return fmt.Errorf("tar exited with code %d: %s", res.ExitCode, msg)
+}
+
+// readTar reads the archive produced by tar cf. dir is the container
+// directory the entries are relative to. Without saveDir content is
+// returned inline within maxInlineTotal and reading stops (truncated) after
+// maxCopyScan bytes.
+func readTar(r io.Reader, dir, saveDir string) (files []copiedFile, truncated bool, err error) {
+	limited := &io.LimitedReader{R: r, N: maxCopyScan}
+	if saveDir != "" {
+		limited.N = maxCopySaved
+	}
+	tr := tar.NewReader(limited)
+	inline := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, false, nil
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				if saveDir != "" {
+					return files, false, fmt.Errorf("copy exceeds %d GiB", maxCopySaved>>30)
+				}
+				return files, true, nil
+			}
+			return files, false, fmt.Errorf("reading archive: %w", err)
+		}
+		f := copiedFile{Path: path.Join(dir, hdr.Name), Size: hdr.Size, Mode: fmt.Sprintf("%04o", hdr.Mode&0o7777)}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeSymlink:
+			f.Type, f.Target = "symlink", hdr.Linkname
+			files = append(files, f)
+			continue
+		default:
+			// directories and special files
+			continue
+		}
+		switch {
+		case saveDir != "":
+			// Clean against a root so ".." cannot climb out of saveDir
+			dst := filepath.Join(saveDir, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+			if err := saveFile(dst, tr); err != nil {
+				if limited.N <= 0 {
+					return files, false, fmt.Errorf("copy exceeds %d GiB", maxCopySaved>>30)
+				}
+				return files, false, err
+			}
+			f.URI = (&url.URL{Scheme: "file", Path: filepath.ToSlash(dst)}).String()
+		case hdr.Size > int64(maxInlineTotal-inline):
+			f.Omitted = "too large to return inline; set save=true to save it locally"
+		default:
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				if limited.N <= 0 {
+					return files, true, nil
+				}
+				return files, false, fmt.Errorf("reading archive: %w", err)
+			}
+			inline += len(b)
+			if len(b) <= maxInlineText && utf8.Valid(b) && bytes.IndexByte(b, 0) < 0 {
+				f.Encoding, f.Content = "text", string(b)
+			} else {
+				f.Encoding, f.Content = "base64", base64.StdEncoding.EncodeToString(b)
+			}
+		}
+		files = append(files, f)
+	}
+}
+
+func saveFile(dst string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
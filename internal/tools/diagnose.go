@@ -0,0 +1,597 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+const (
+	defaultDiagnoseLogLines = 20
+	maxDiagnoseLogLines     = 200
+	maxEvidenceBytes        = 300
+	// nodeEventWindow is how far back node pressure events count as recent.
+	nodeEventWindow = time.Hour
+)
+
+// exitCodeMeanings explains the exit codes seen most often in crash loops.
+var exitCodeMeanings = map[int32]string{
+	1:   "general application error",
+	2:   "invalid arguments or shell misuse",
+	126: "command not executable (permissions or wrong architecture)",
+	127: "command not found in the image",
+	134: "aborted (SIGABRT)",
+	137: "killed by SIGKILL (out of memory, a failed liveness probe or a forced stop)",
+	139: "segmentation fault (SIGSEGV)",
+	143: "terminated by SIGTERM",
+}
+
+// logSignal matches log lines worth quoting as evidence of a crash.
+var logSignal = regexp.MustCompile(`(?i)(panic|fatal|exception|traceback|error|permission denied|no such file|address already in use|connection refused|out of memory)`)
+
+// podCause is one likely cause of a pod's trouble. Score only ranks causes
+// against each other; it is not a probability.
+type podCause struct {
+	Cause     string   `json:"cause"`
+	Container string   `json:"container,omitempty"`
+	Score     int      `json:"score"`
+	Summary   string   `json:"summary"`
+	Evidence  []string `json:"evidence"`
+	Hint      string   `json:"hint,omitempty"`
+}
+
+// containerReport is the state of one container as pods-diagnose saw it.
+type containerReport struct {
+	Name            string `json:"name"`
+	Init            bool   `json:"init,omitempty"`
+	Image           string `json:"image"`
+	Ready           bool   `json:"ready"`
+	RestartCount    int32  `json:"restartCount"`
+	State           string `json:"state"`
+	Reason          string `json:"reason,omitempty"`
+	ExitCode        *int32 `json:"exitCode,omitempty"`
+	LastTermination string `json:"lastTermination,omitempty"`
+}
+
+// diagnosis collects causes, merging evidence for the same cause and container.
+type diagnosis struct {
+	causes map[string]*podCause
+}
+
+func (d *diagnosis) add(cause, container string, score int, summary, hint string, evidence ...string) {
+	key := cause + "/" + container
+	c := d.causes[key]
+	if c == nil {
+		c = &podCause{Cause: cause, Container: container, Summary: summary, Hint: hint, Evidence: []string{}}
+		d.causes[key] = c
+	}
+	c.Score = max(c.Score, score)
+	c.note(evidence...)
+}
+
+func (c *podCause) note(evidence ...string) {
+	for _, e := range evidence {
+		if len(e) > maxEvidenceBytes {
+			e = strings.ToValidUTF8(e[:maxEvidenceBytes], "") + "…"
+		}
+		c.Evidence = append(c.Evidence, e)
+	}
+}
+
+func (d *diagnosis) has(container string) bool {
+	for _, c := range d.causes {
+		if c.Container == container {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *diagnosis) ranked() []podCause {
+	out := make([]podCause, 0, len(d.causes))
+	for _, c := range d.causes {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Cause != out[j].Cause {
+			return out[i].Cause < out[j].Cause
+		}
+		return out[i].Container < out[j].Container
+	})
+	return out
+}
+
+func RegisterDiagnose(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "pods-diagnose", Description: "Explain why a pod or workload is crashing or not ready: a ranked list of likely causes with evidence", DirectResult: true, Handler: notReady})
+		return
+	}
+	// pods-diagnose
+	reg.Register(mcp.Tool{
+		Name:         "pods-diagnose",
+		Description:  "Explain why a pod or workload is crashing or not ready: a ranked list of likely causes with evidence",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("pods-diagnose", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace, Name string
+				Kind            string // a workload kind; empty or Pod for a pod
+				LogLines        int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			if p.Name == "" {
+				return nil, errors.New("name is required")
+			}
+			if p.LogLines <= 0 {
+				p.LogLines = defaultDiagnoseLogLines
+			}
+			p.LogLines = min(p.LogLines, maxDiagnoseLogLines)
+
+			var pod *corev1.Pod
+			var overview []map[string]any
+			if p.Kind == "" || strings.EqualFold(p.Kind, "pod") || strings.EqualFold(p.Kind, "pods") || strings.EqualFold(p.Kind, "po") {
+				var err error
+				if pod, err = k.Clientset.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{}); err != nil {
+					return nil, err
+				}
+			} else {
+				sel, err := podSelector(ctx, k, p.Namespace, p.Kind, p.Name, "")
+				if err != nil {
+					return nil, err
+				}
+				pods, err := k.Clientset.CoreV1().Pods(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+				if err != nil {
+					return nil, err
+				}
+				if len(pods.Items) == 0 {
+					return nil, fmt.Errorf("%s %s has no pods (selector %s)", p.Kind, p.Name, sel)
+				}
+				// diagnose the pod in the worst shape; list the rest
+				sort.Slice(pods.Items, func(i, j int) bool {
+					bi, bj := podBadness(&pods.Items[i]), podBadness(&pods.Items[j])
+					if bi != bj {
+						return bi > bj
+					}
+					return pods.Items[i].Name < pods.Items[j].Name
+				})
+				pod = &pods.Items[0]
+				for i := range pods.Items {
+					if i == 20 {
+						break
+					}
+					q := &pods.Items[i]
+					overview = append(overview, map[string]any{"name": q.Name, "phase": q.Status.Phase, "ready": podReady(q), "restarts": podRestarts(q)})
+				}
+			}
+			out := diagnosePod(ctx, k, pod, p.LogLines)
+			if overview != nil {
+				out["workload"] = map[string]any{"kind": canonicalKind(p.Kind), "name": p.Name, "pods": overview}
+			}
+			return out, nil
+		}),
+	})
+}
+
+// podBadness orders a workload's pods so the least healthy comes first.
+func podBadness(pod *corev1.Pod) int {
+	score := min(int(podRestarts(pod)), 20)
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		score += 50
+	case corev1.PodFailed, corev1.PodUnknown:
+		score += 60
+	}
+	if !podReady(pod) {
+		score += 10
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if w := cs.State.Waiting; w != nil && w.Reason != "ContainerCreating" && w.Reason != "PodInitializing" {
+			score += 40
+		}
+	}
+	return score
+}
+
+func podRestarts(pod *corev1.Pod) int32 {
+	var n int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		n += cs.RestartCount
+	}
+	return n
+}
+
+// diagnosePod gathers container state, events, config references, node
+// conditions and the crashing container's log into ranked causes.
+func diagnosePod(ctx context.Context, k *k8s.Clients, pod *corev1.Pod, logLines int) map[string]any {
+	d := &diagnosis{causes: map[string]*podCause{}}
+	out := map[string]any{"pod": pod.Name, "namespace": pod.Namespace, "phase": pod.Status.Phase, "ready": podReady(pod), "restarts": podRestarts(pod)}
+	if pod.Spec.NodeName != "" {
+		out["node"] = pod.Spec.NodeName
+	}
+
+	// scheduling and eviction
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			d.add("Unschedulable", "", 95, "the pod cannot be scheduled on any node", "run pods-why-pending for a per-node breakdown", c.Reason+": "+c.Message)
+		}
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		if pod.Status.Reason == "Evicted" {
+			d.add("Evicted", "", 95, "the kubelet evicted the pod", "check node pressure and the pod's requests; evicted pods are not restarted in place", pod.Status.Message)
+		} else if pod.Status.Reason != "" {
+			d.add("PodFailed", "", 70, "the pod failed: "+pod.Status.Reason, "", pod.Status.Message)
+		}
+	}
+
+	// container state
+	var reports []containerReport
+	specs := map[string]*corev1.Container{}
+	for i := range pod.Spec.InitContainers {
+		specs[pod.Spec.InitContainers[i].Name] = &pod.Spec.InitContainers[i]
+	}
+	for i := range pod.Spec.Containers {
+		specs[pod.Spec.Containers[i].Name] = &pod.Spec.Containers[i]
+	}
+	restartAlways := pod.Spec.RestartPolicy == "" || pod.Spec.RestartPolicy == corev1.RestartPolicyAlways
+	check := func(cs corev1.ContainerStatus, init bool) {
+		r := containerReport{Name: cs.Name, Init: init, Image: containerImage(pod, cs.Name), Ready: cs.Ready, RestartCount: cs.RestartCount, State: "waiting"}
+		switch {
+		case cs.State.Running != nil:
+			r.State = "running"
+		case cs.State.Terminated != nil:
+			r.State, r.Reason, r.ExitCode = "terminated", cs.State.Terminated.Reason, &cs.State.Terminated.ExitCode
+		case cs.State.Waiting != nil:
+			r.Reason = cs.State.Waiting.Reason
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil {
+			r.LastTermination = describeTermination(t)
+		}
+		reports = append(reports, r)
+
+		if w := cs.State.Waiting; w != nil {
+			waiting := fmt.Sprintf("container %s waiting: %s: %s", cs.Name, w.Reason, w.Message)
+			switch w.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull", "RegistryUnavailable":
+				d.add("ImagePullError", cs.Name, 95, fmt.Sprintf("image %s cannot be pulled", r.Image), "check the image name and tag, registry reachability and imagePullSecrets", waiting)
+			case "CreateContainerConfigError", "CreateContainerError":
+				d.add("ContainerConfigError", cs.Name, 90, "the container cannot be created from its spec", "a referenced ConfigMap, Secret or key is usually missing", waiting)
+			case "RunContainerError", "StartError":
+				d.add("ContainerStartError", cs.Name, 85, "the container runtime failed to start the process", "check command, args, working directory and volume mounts", waiting)
+			}
+		}
+		for _, t := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if t == nil {
+				continue
+			}
+			ev := fmt.Sprintf("container %s terminated: %s", cs.Name, describeTermination(t))
+			if t.Message != "" {
+				ev += ": " + t.Message
+			}
+			switch {
+			case t.Reason == "OOMKilled":
+				limit := "no memory limit is set, so the node ran out of memory"
+				if spec := specs[cs.Name]; spec != nil {
+					if q, ok := spec.Resources.Limits[corev1.ResourceMemory]; ok {
+						limit = "memory limit is " + q.String()
+					}
+				}
+				d.add("OOMKilled", cs.Name, 95, fmt.Sprintf("container %s is killed for using too much memory", cs.Name), "raise the memory limit or find the leak; top-pods shows current usage", ev, limit)
+			case t.Reason == "StartError" || t.Reason == "ContainerCannotRun":
+				d.add("ContainerStartError", cs.Name, 85, "the container runtime failed to start the process", "check command, args, working directory and volume mounts", ev)
+			case t.ExitCode != 0:
+				summary := fmt.Sprintf("container %s exits with code %d", cs.Name, t.ExitCode)
+				if m := exitCodeMeanings[t.ExitCode]; m != "" {
+					summary += ": " + m
+				}
+				d.add("ContainerExitError", cs.Name, 80, summary, "the log tail below usually shows why", ev)
+			case cs.RestartCount > 0 && restartAlways && !init:
+				d.add("ExitsSuccessfully", cs.Name, 50, fmt.Sprintf("container %s keeps exiting with code 0 and is restarted because restartPolicy is Always", cs.Name), "the main process should not exit; check command and args", ev)
+			}
+		}
+		if w := cs.State.Waiting; w != nil && w.Reason == "CrashLoopBackOff" {
+			for _, c := range d.causes {
+				if c.Container == cs.Name {
+					c.Score += 5
+					c.note(fmt.Sprintf("container %s in CrashLoopBackOff after %d restarts", cs.Name, cs.RestartCount))
+				}
+			}
+			if !d.has(cs.Name) {
+				d.add("CrashLoopBackOff", cs.Name, 70, fmt.Sprintf("container %s is crash looping", cs.Name), "the log tail below usually shows why", fmt.Sprintf("%s: %s", w.Reason, w.Message))
+			}
+		}
+		if cs.RestartCount > 0 && !d.has(cs.Name) {
+			d.add("Restarting", cs.Name, 40, fmt.Sprintf("container %s restarted %d times", cs.Name, cs.RestartCount), "check the previous log and events", fmt.Sprintf("restartCount %d", cs.RestartCount))
+		}
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		check(cs, true)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		check(cs, false)
+	}
+	out["containers"] = reports
+
+	// events
+	var podEvents []eventRecord
+	if events, err := listEvents(ctx, k, pod.Namespace, eventFilter{Kind: "Pod", Name: pod.Name}); err == nil {
+		for _, e := range events {
+			if e.Kind == "Pod" && e.Name == pod.Name {
+				podEvents = append(podEvents, e)
+			}
+		}
+	}
+	sort.SliceStable(podEvents, func(i, j int) bool { return podEvents[i].LastSeen.After(podEvents[j].LastSeen) })
+	var warnings []eventRecord
+	for _, e := range podEvents {
+		if e.Type != corev1.EventTypeWarning {
+			continue
+		}
+		warnings = append(warnings, e)
+		ev := eventEvidence(e)
+		msg := strings.ToLower(e.Message)
+		switch {
+		case strings.HasPrefix(msg, "liveness probe failed") || strings.Contains(msg, "failed liveness probe"):
+			d.add("LivenessProbeFailing", "", 85, "the liveness probe fails and the kubelet restarts the container", "check the probe's path, port and timeouts against the app's startup time; a startupProbe helps slow starters", ev)
+		case strings.HasPrefix(msg, "startup probe failed") || strings.Contains(msg, "failed startup probe"):
+			d.add("StartupProbeFailing", "", 80, "the startup probe fails and the kubelet restarts the container", "raise failureThreshold or periodSeconds if the app is just slow to start", ev)
+		case strings.HasPrefix(msg, "readiness probe failed"):
+			d.add("ReadinessProbeFailing", "", 60, "the readiness probe fails, so the pod receives no Service traffic", "check the probe's path and port and the app's dependencies", ev)
+		case e.Reason == "Failed" && strings.Contains(msg, "pull"), e.Reason == "ErrImagePull":
+			d.add("ImagePullError", "", 90, "an image cannot be pulled", "check the image name and tag, registry reachability and imagePullSecrets", ev)
+		case e.Reason == "FailedMount" || e.Reason == "FailedAttachVolume":
+			d.add("VolumeMountFailure", "", 80, "a volume cannot be attached or mounted", "check the referenced ConfigMaps, Secrets and PVCs and the storage driver", ev)
+		case e.Reason == "BackOff":
+			for _, c := range d.causes {
+				if c.Container != "" {
+					c.note(ev)
+				}
+			}
+		}
+	}
+	if len(warnings) > 10 {
+		warnings = warnings[:10]
+	}
+	out["events"] = warnings
+
+	missingReferences(ctx, k, pod, d)
+	nodeFindings(ctx, k, pod, d)
+
+	// the tail of the crashing container's log
+	if name, previous := crashedContainer(pod); name != "" {
+		tail := int64(logLines)
+		q, _ := newLogQuery(logParams{TailLines: &tail, Previous: previous})
+		cl, err := q.fetch(ctx, k, pod.Namespace, pod.Name, name)
+		if err != nil && previous {
+			q.opts.Previous, previous = false, false
+			cl, err = q.fetch(ctx, k, pod.Namespace, pod.Name, name)
+		}
+		logs := map[string]any{"container": name, "previous": previous}
+		if err != nil {
+			logs["error"] = err.Error()
+		} else {
+			logs["lines"] = splitLogLines(cl.Logs)
+			var signals []string
+			for _, l := range splitLogLines(cl.Logs) {
+				if logSignal.MatchString(l) {
+					signals = append(signals, "log: "+strings.TrimSpace(l))
+				}
+			}
+			if len(signals) > 3 {
+				signals = signals[len(signals)-3:]
+			}
+			for _, c := range d.causes {
+				if c.Container == name {
+					c.note(signals...)
+				}
+			}
+		}
+		out["logs"] = logs
+	}
+
+	causes := d.ranked()
+	out["causes"] = causes
+	switch {
+	case len(causes) > 0:
+		out["summary"] = causes[0].Summary
+	case pod.Status.Phase == corev1.PodRunning && podReady(pod):
+		out["summary"] = "no problem found: the pod is running and ready"
+	default:
+		out["summary"] = fmt.Sprintf("no known failure pattern found; the pod is %s", pod.Status.Phase)
+	}
+	return out
+}
+
+func describeTermination(t *corev1.ContainerStateTerminated) string {
+	s := fmt.Sprintf("%s (exit %d)", t.Reason, t.ExitCode)
+	if !t.FinishedAt.IsZero() {
+		s += " at " + t.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return s
+}
+
+func eventEvidence(e eventRecord) string {
+	s := fmt.Sprintf("event %s: %s", e.Reason, e.Message)
+	if e.Count > 1 {
+		s += fmt.Sprintf(" (x%d)", e.Count)
+	}
+	if !e.LastSeen.IsZero() {
+		s += fmt.Sprintf(", last seen %s ago", time.Since(e.LastSeen).Round(time.Second))
+	}
+	return s
+}
+
+// crashedContainer picks the container whose log explains a crash: the one
+// with the most restarts (its previous instance), else one that terminated
+// non-zero.
+func crashedContainer(pod *corev1.Pod) (string, bool) {
+	name, restarts := "", int32(0)
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.RestartCount > restarts {
+			name, restarts = cs.Name, cs.RestartCount
+		}
+	}
+	if name != "" {
+		return name, true
+	}
+	for _, cs := range statuses {
+		if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+			return cs.Name, false
+		}
+	}
+	return "", false
+}
+
+// configRef is a ConfigMap or Secret the pod needs.
+type configRef struct {
+	kind, name, key, where string
+}
+
+// missingReferences reports ConfigMaps, Secrets and keys the pod refers to
+// without marking them optional that do not exist. Objects it may not read
+// are skipped.
+func missingReferences(ctx context.Context, k *k8s.Clients, pod *corev1.Pod, d *diagnosis) {
+	var refs []configRef
+	optional := func(o *bool) bool { return o != nil && *o }
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.ConfigMap != nil && !optional(v.ConfigMap.Optional):
+			refs = append(refs, configRef{"ConfigMap", v.ConfigMap.Name, "", "volume " + v.Name})
+		case v.Secret != nil && !optional(v.Secret.Optional):
+			refs = append(refs, configRef{"Secret", v.Secret.SecretName, "", "volume " + v.Name})
+		case v.Projected != nil:
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil && !optional(s.ConfigMap.Optional) {
+					refs = append(refs, configRef{"ConfigMap", s.ConfigMap.Name, "", "volume " + v.Name})
+				}
+				if s.Secret != nil && !optional(s.Secret.Optional) {
+					refs = append(refs, configRef{"Secret", s.Secret.Name, "", "volume " + v.Name})
+				}
+			}
+		}
+	}
+	for _, list := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range list {
+			for _, ef := range c.EnvFrom {
+				if ef.ConfigMapRef != nil && !optional(ef.ConfigMapRef.Optional) {
+					refs = append(refs, configRef{"ConfigMap", ef.ConfigMapRef.Name, "", "container " + c.Name + " envFrom"})
+				}
+				if ef.SecretRef != nil && !optional(ef.SecretRef.Optional) {
+					refs = append(refs, configRef{"Secret", ef.SecretRef.Name, "", "container " + c.Name + " envFrom"})
+				}
+			}
+			for _, e := range c.Env {
+				if e.ValueFrom == nil {
+					continue
+				}
+				if r := e.ValueFrom.ConfigMapKeyRef; r != nil && !optional(r.Optional) {
+					refs = append(refs, configRef{"ConfigMap", r.Name, r.Key, "container " + c.Name + " env " + e.Name})
+				}
+				if r := e.ValueFrom.SecretKeyRef; r != nil && !optional(r.Optional) {
+					refs = append(refs, configRef{"Secret", r.Name, r.Key, "container " + c.Name + " env " + e.Name})
+				}
+			}
+		}
+	}
+	for _, s := range pod.Spec.ImagePullSecrets {
+		refs = append(refs, configRef{"Secret", s.Name, "", "imagePullSecrets"})
+	}
+
+	// keys per object, nil when the object is missing
+	type lookup struct {
+		keys map[string]bool
+		err  error
+	}
+	seen := map[string]lookup{}
+	for _, r := range refs {
+		id := r.kind + "/" + r.name
+		l, ok := seen[id]
+		if !ok {
+			l.keys = map[string]bool{}
+			if r.kind == "ConfigMap" {
+				cm, err := k.Clientset.CoreV1().ConfigMaps(pod.Namespace).Get(ctx, r.name, metav1.GetOptions{})
+				l.err = err
+				if err == nil {
+					for key := range cm.Data {
+						l.keys[key] = true
+					}
+					for key := range cm.BinaryData {
+						l.keys[key] = true
+					}
+				}
+			} else {
+				sec, err := k.Clientset.CoreV1().Secrets(pod.Namespace).Get(ctx, r.name, metav1.GetOptions{})
+				l.err = err
+				if err == nil {
+					for key := range sec.Data {
+						l.keys[key] = true
+					}
+				}
+			}
+			seen[id] = l
+		}
+		switch {
+		case apierrors.IsNotFound(l.err):
+			d.add("Missing"+r.kind, "", 90, fmt.Sprintf("a referenced %s does not exist", r.kind), "create it or mark the reference optional", fmt.Sprintf("%s %s not found (used by %s)", r.kind, r.name, r.where))
+		case l.err == nil && r.key != "" && !l.keys[r.key]:
+			d.add("Missing"+r.kind+"Key", "", 90, fmt.Sprintf("a referenced %s key does not exist", r.kind), "add the key or fix the reference", fmt.Sprintf("%s %s has no key %q (used by %s)", r.kind, r.name, r.key, r.where))
+		}
+	}
+}
+
+// nodeFindings reports the pod's node when it is not ready, under pressure
+// now, or reported pressure or OOM kills within nodeEventWindow.
+func nodeFindings(ctx context.Context, k *k8s.Clients, pod *corev1.Pod, d *diagnosis) {
+	if pod.Spec.NodeName == "" {
+		return
+	}
+	node, err := k.Clientset.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	if status := nodeStatus(node); !strings.HasPrefix(status, "Ready") {
+		d.add("NodeNotReady", "", 75, fmt.Sprintf("node %s is not ready", node.Name), "check the kubelet and the node with nodes-get", "node status "+status)
+	}
+	if p := nodePressure(node); len(p) > 0 {
+		d.add("NodePressure", "", 55, fmt.Sprintf("node %s is under pressure", node.Name), "pods on a pressured node may be evicted or OOM-killed; check top-nodes", "node conditions: "+strings.Join(p, ", "))
+	}
+	// node events are recorded in the default namespace
+	events, err := listEvents(ctx, k, metav1.NamespaceDefault, eventFilter{Kind: "Node", Name: node.Name})
+	if err != nil {
+		return
+	}
+	for _, e := range events {
+		if e.Kind != "Node" || e.Name != node.Name || time.Since(e.LastSeen) > nodeEventWindow {
+			continue
+		}
+		switch e.Reason {
+		case "SystemOOM", "EvictionThresholdMet", "NodeHasInsufficientMemory", "NodeHasDiskPressure", "NodeHasInsufficientPID", "FreeDiskSpaceFailed":
+			d.add("NodePressure", "", 55, fmt.Sprintf("node %s is under pressure", node.Name), "pods on a pressured node may be evicted or OOM-killed; check top-nodes", eventEvidence(e))
+		}
+	}
+}
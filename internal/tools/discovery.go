@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+func RegisterDiscovery(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "api-resources", Description: "List API resources served by the cluster (kind, plural, short names, scope, verbs)", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "resources-explain", Description: "Explain the schema of a kind or one of its fields from the cluster's OpenAPI v3 document (works for CRDs)", DirectResult: true, Handler: notReady})
+		return
+	}
+	// api-resources
+	reg.Register(mcp.Tool{
+		Name:         "api-resources",
+		Description:  "List API resources served by the cluster (kind, plural, short names, scope, verbs)",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("api-resources", 10, 5)
+			k := k.Current()
+			var p struct {
+				Group       *string // "" selects the core group
+				Verb        string
+				Namespaced  *bool
+				AllVersions bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			groups, lists, err := k.Discovery.ServerGroupsAndResources()
+			var failed []string
+			if err != nil {
+				// aggregated APIs that are down fail discovery for their group
+				// only; report them and keep the rest
+				var gdf *discovery.ErrGroupDiscoveryFailed
+				if !errors.As(err, &gdf) {
+					return nil, err
+				}
+				for gv, gerr := range gdf.Groups {
+					failed = append(failed, gv.String()+": "+gerr.Error())
+				}
+				sort.Strings(failed)
+			}
+			preferred := map[string]string{}
+			for _, g := range groups {
+				if g != nil {
+					preferred[g.Name] = g.PreferredVersion.Version
+				}
+			}
+			type row struct {
+				Name       string   `json:"name"`
+				ShortNames []string `json:"shortNames,omitempty"`
+				APIVersion string   `json:"apiVersion"`
+				Group      string   `json:"group"`
+				Version    string   `json:"version"`
+				Kind       string   `json:"kind"`
+				Namespaced bool     `json:"namespaced"`
+				Verbs      []string `json:"verbs"`
+				Categories []string `json:"categories,omitempty"`
+			}
+			rows := []row{}
+			for _, list := range lists {
+				if list == nil {
+					continue
+				}
+				gv, err := schema.ParseGroupVersion(list.GroupVersion)
+				if err != nil {
+					continue
+				}
+				if !p.AllVersions && preferred[gv.Group] != "" && preferred[gv.Group] != gv.Version {
+					continue
+				}
+				if p.Group != nil && *p.Group != gv.Group {
+					continue
+				}
+				for _, r := range list.APIResources {
+					// subresources such as pods/log are not listable kinds
+					if strings.Contains(r.Name, "/") {
+						continue
+					}
+					if p.Namespaced != nil && *p.Namespaced != r.Namespaced {
+						continue
+					}
+					if p.Verb != "" && !slices.Contains(r.Verbs, p.Verb) {
+						continue
+					}
+					rows = append(rows, row{Name: r.Name, ShortNames: r.ShortNames, APIVersion: list.GroupVersion, Group: gv.Group, Version: gv.Version, Kind: r.Kind, Namespaced: r.Namespaced, Verbs: r.Verbs, Categories: r.Categories})
+				}
+			}
+			sort.Slice(rows, func(i, j int) bool {
+				if rows[i].Group != rows[j].Group {
+					return rows[i].Group < rows[j].Group
+				}
+				if rows[i].Name != rows[j].Name {
+					return rows[i].Name < rows[j].Name
+				}
+				return rows[i].Version < rows[j].Version
+			})
+			out := map[string]any{"resources": rows}
+			if len(failed) > 0 {
+				out["failedGroups"] = failed
+			}
+			return out, nil
+		}),
+	})
+
+	// resources-explain
+	reg.Register(mcp.Tool{
+		Name:         "resources-explain",
+		Description:  "Explain the schema of a kind or one of its fields from the cluster's OpenAPI v3 document (works for CRDs)",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("resources-explain", 10, 5)
+			k := k.Current()
+			var p struct {
+				Group     *string
+				Version   string
+				Kind      string
+				Field     string // e.g. spec.template.spec.containers.resources
+				Recursive bool
+				MaxDepth  int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Kind == "" {
+				return nil, errors.New("kind is required")
+			}
+			if k.OpenAPI == nil {
+				return nil, errors.New("resources-explain requires a live API server (no OpenAPI v3 document on this backend)")
+			}
+			gvk := schema.GroupVersionKind{Version: p.Version, Kind: p.Kind}
+			if p.Group != nil {
+				gvk.Group = *p.Group
+			} else {
+				// without a group, find the kind in whichever group serves it
+				found, err := kindAcrossGroups(k, p.Kind, p.Version)
+				if err != nil {
+					return nil, err
+				}
+				gvk = found
+			}
+			// without a version, explain the version the server prefers
+			if gvk.Version == "" {
+				mapping, err := k.Mapper.RESTMapping(gvk.GroupKind())
+				if err != nil {
+					return nil, err
+				}
+				gvk = mapping.GroupVersionKind
+			}
+			if p.MaxDepth <= 0 || p.MaxDepth > 15 {
+				p.MaxDepth = 15
+			}
+			// Documents are cached, so on an error or a missing kind (e.g. a
+			// CRD installed since) the cache is dropped and the server asked again.
+			doc, err := loadOpenAPIDoc(k.OpenAPI, gvk.GroupVersion())
+			if err == nil {
+				_, err = doc.kindSchema(gvk)
+			}
+			if err != nil {
+				k.ResetOpenAPI()
+				if doc, err = loadOpenAPIDoc(k.OpenAPI, gvk.GroupVersion()); err != nil {
+					return nil, err
+				}
+			}
+			return doc.explain(gvk, p.Field, p.Recursive, p.MaxDepth)
+		}),
+	})
+}
+
+// kindAcrossGroups resolves a kind given without its group through the
+// RESTMapper, as kubectl explain does: when several groups serve the kind the
+// one discovery lists first wins (core, then apps, before extensions), in the
+// server's preferred version unless version is set.
+func kindAcrossGroups(k *k8s.Clients, kind, version string) (schema.GroupVersionKind, error) {
+	gvr := schema.GroupVersionResource{Version: version, Resource: strings.ToLower(kind)}
+	gvks, err := k.Mapper.KindsFor(gvr)
+	if err != nil && meta.IsNoMatchError(err) {
+		// discovery is cached; a CRD installed since needs a fresh look
+		if rm, ok := k.Mapper.(meta.ResettableRESTMapper); ok {
+			rm.Reset()
+			gvks, err = k.Mapper.KindsFor(gvr)
+		}
+	}
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	if len(gvks) == 0 {
+		return schema.GroupVersionKind{}, fmt.Errorf("kind %s not found in any API group", kind)
+	}
+	return gvks[0], nil
+}
@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// eventRecord is an event normalized across core/v1 and events.k8s.io/v1.
+type eventRecord struct {
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	Source    string    `json:"source,omitempty"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	uid       string
+}
+
+func RegisterEvents(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "events-list", Description: "List events across namespaces with filtering, aggregation and last-seen ordering", DirectResult: true, Handler: notReady})
+		return
+	}
+	// events-list
+	reg.Register(mcp.Tool{
+		Name:         "events-list",
+		Description:  "List events across namespaces with filtering, aggregation and last-seen ordering",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("events-list", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace string // empty lists all namespaces
+				Kind      string
+				Name      string
+				Type      string
+				Reason    string
+				Since     string // Go duration, e.g. 30m or 2h
+				Aggregate *bool
+				Limit     int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			var since time.Time
+			if p.Since != "" {
+				d, err := time.ParseDuration(p.Since)
+				if err != nil {
+					return nil, fmt.Errorf("invalid since %q: %w", p.Since, err)
+				}
+				since = time.Now().Add(-d)
+			}
+			if p.Limit <= 0 {
+				p.Limit = 100
+			}
+			events, err := listEvents(ctx, k, p.Namespace, eventFilter{Kind: p.Kind, Name: p.Name, Type: p.Type, Reason: p.Reason})
+			if err != nil {
+				return nil, err
+			}
+			var rows []eventRecord
+			for _, e := range events {
+				switch {
+				case e.Namespace != "" && !authz.IsNamespaceAllowed(e.Namespace):
+				case p.Kind != "" && !strings.EqualFold(p.Kind, e.Kind):
+				case p.Name != "" && p.Name != e.Name:
+				case p.Type != "" && !strings.EqualFold(p.Type, e.Type):
+				case p.Reason != "" && !strings.EqualFold(p.Reason, e.Reason):
+				case !since.IsZero() && e.LastSeen.Before(since):
+				default:
+					rows = append(rows, e)
+				}
+			}
+			if p.Aggregate == nil || *p.Aggregate {
+				rows = aggregateEvents(rows)
+			}
+			sort.SliceStable(rows, func(i, j int) bool { return rows[i].LastSeen.After(rows[j].LastSeen) })
+			total := len(rows)
+			if len(rows) > p.Limit {
+				rows = rows[:p.Limit]
+			}
+			if rows == nil {
+				rows = []eventRecord{}
+			}
+			return map[string]any{"events": rows, "total": total}, nil
+		}),
+	})
+}
+
+// eventFilter selects events on the API server; empty fields match all.
+// Field selectors compare exactly, so type is canonicalized here and kind by
+// listEvents (see eventKind).
+type eventFilter struct {
+	Kind, Name, Type, Reason string
+}
+
+// selector renders f as a field selector; object is the field naming the
+// event's subject (involvedObject in core/v1, regarding in events.k8s.io/v1).
+func (f eventFilter) selector(object string) string {
+	set := fields.Set{}
+	if f.Kind != "" {
+		set[object+".kind"] = f.Kind
+	}
+	if f.Name != "" {
+		set[object+".name"] = f.Name
+	}
+	if f.Type != "" {
+		typ := f.Type
+		for _, t := range []string{corev1.EventTypeNormal, corev1.EventTypeWarning} {
+			if strings.EqualFold(typ, t) {
+				typ = t
+			}
+		}
+		set["type"] = typ
+	}
+	if f.Reason != "" {
+		set["reason"] = f.Reason
+	}
+	return fields.SelectorFromSet(set).String()
+}
+
+// eventKind resolves a kind as a user may spell it ("configmap", "deploy",
+// "HorizontalPodAutoscaler") to the kind events record, through the aliases
+// and the RESTMapper. It returns "" when the kind cannot be resolved.
+func eventKind(k *k8s.Clients, kind string) string {
+	if kind == "" {
+		return ""
+	}
+	if c := canonicalKind(kind); c != "" {
+		return c
+	}
+	if k.Mapper == nil {
+		return ""
+	}
+	// the mapper accepts the lowercase singular as a resource name
+	gvks, err := k.Mapper.KindsFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+	if err != nil || len(gvks) == 0 {
+		return ""
+	}
+	for _, gvk := range gvks[1:] {
+		if gvk.Kind != gvks[0].Kind {
+			return ""
+		}
+	}
+	return gvks[0].Kind
+}
+
+// listEvents reads both event APIs and merges them by UID; on a real cluster
+// they are two views of the same objects, but either may be unavailable
+// (old servers, RBAC granted on only one group).
+func listEvents(ctx context.Context, k *k8s.Clients, ns string, f eventFilter) ([]eventRecord, error) {
+	// a kind the server cannot confirm is left to the caller's own filter
+	f.Kind = eventKind(k, f.Kind)
+	seen := map[string]bool{}
+	var out []eventRecord
+	add := func(r eventRecord) {
+		if r.uid != "" && seen[r.uid] {
+			return
+		}
+		seen[r.uid] = true
+		out = append(out, r)
+	}
+	newList, newErr := k.Clientset.EventsV1().Events(ns).List(ctx, metav1.ListOptions{FieldSelector: f.selector("regarding")})
+	if newErr == nil {
+		for i := range newList.Items {
+			add(fromEventsV1(&newList.Items[i]))
+		}
+	}
+	coreList, coreErr := k.Clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{FieldSelector: f.selector("involvedObject")})
+	if coreErr == nil {
+		for i := range coreList.Items {
+			add(fromCoreEvent(&coreList.Items[i]))
+		}
+	}
+	if newErr != nil && coreErr != nil {
+		return nil, coreErr
+	}
+	return out, nil
+}
+
+func fromCoreEvent(e *corev1.Event) eventRecord {
+	r := eventRecord{
+		Namespace: e.Namespace, Type: e.Type, Reason: e.Reason, Message: e.Message,
+		Kind: e.InvolvedObject.Kind, Name: e.InvolvedObject.Name,
+		Source: e.Source.Component, Count: e.Count, uid: string(e.UID),
+	}
+	if r.Source == "" {
+		r.Source = e.ReportingController
+	}
+	if e.Series != nil && e.Series.Count > r.Count {
+		r.Count = e.Series.Count
+	}
+	r.FirstSeen = firstTime(e.FirstTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+	r.LastSeen = latestTime(e.LastTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+	if e.Series != nil {
+		r.LastSeen = latestTime(r.LastSeen, e.Series.LastObservedTime.Time)
+	}
+	if r.Count == 0 {
+		r.Count = 1
+	}
+	return r
+}
+
+func fromEventsV1(e *eventsv1.Event) eventRecord {
+	r := eventRecord{
+		Namespace: e.Namespace, Type: e.Type, Reason: e.Reason, Message: e.Note,
+		Kind: e.Regarding.Kind, Name: e.Regarding.Name,
+		Source: e.ReportingController, Count: e.DeprecatedCount, uid: string(e.UID),
+	}
+	if r.Source == "" {
+		r.Source = e.DeprecatedSource.Component
+	}
+	if e.Series != nil && e.Series.Count > r.Count {
+		r.Count = e.Series.Count
+	}
+	r.FirstSeen = firstTime(e.DeprecatedFirstTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+	r.LastSeen = latestTime(e.DeprecatedLastTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+	if e.Series != nil {
+		r.LastSeen = latestTime(r.LastSeen, e.Series.LastObservedTime.Time)
+	}
+	if r.Count == 0 {
+		r.Count = 1
+	}
+	return r
+}
+
+// eventLastSeen is when a core event last occurred. LastTimestamp is unset
+// for events written through events.k8s.io, which record EventTime and a
+// Series instead.
+func eventLastSeen(e *corev1.Event) time.Time {
+	return fromCoreEvent(e).LastSeen
+}
+
+// aggregateEvents folds events for the same object, type, reason and
+// message into one row with summed counts.
+func aggregateEvents(rows []eventRecord) []eventRecord {
+	index := map[string]int{}
+	var out []eventRecord
+	for _, r := range rows {
+		key := strings.Join([]string{r.Namespace, r.Kind, r.Name, r.Type, r.Reason, r.Message}, "\x00")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, r)
+			continue
+		}
+		agg := &out[i]
+		agg.Count += r.Count
+		agg.FirstSeen = firstTime(agg.FirstSeen, r.FirstSeen)
+		agg.LastSeen = latestTime(agg.LastSeen, r.LastSeen)
+	}
+	return out
+}
+
+func latestTime(ts ...time.Time) time.Time {
+	var out time.Time
+	for _, t := range ts {
+		if t.After(out) {
+			out = t
+		}
+	}
+	return out
+}
+
+func firstTime(ts ...time.Time) time.Time {
+	var out time.Time
+	for _, t := range ts {
+		if !t.IsZero() && (out.IsZero() || t.Before(out)) {
+			out = t
+		}
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+)
+
+const (
+	defaultExecOutput  = 64 << 10
+	maxExecOutput      = 1 << 20
+	defaultExecTimeout = 30 * time.Second
+	maxExecTimeout     = 5 * time.Minute
+)
+
+// podExecutor opens the exec stream for a pod. Like kubectl it speaks
+// WebSocket and falls back to SPDY when the server cannot upgrade. Tests
+// replace it to run without an API server.
+var podExecutor = func(k *k8s.Clients, namespace, pod string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+	if k.RestConfig == nil {
+		return nil, errors.New("pods-exec requires a live API server")
+	}
+	req := k.Clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("exec")
+	req.VersionedParams(opts, scheme.ParameterCodec)
+	spdyExec, err := remotecommand.NewSPDYExecutor(k.RestConfig, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+	wsExec, err := remotecommand.NewWebSocketExecutor(k.RestConfig, "GET", req.URL().String())
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(wsExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// execRequest describes one command run in a container.
+type execRequest struct {
+	Namespace, Pod, Container string
+	Command                   []string
+	Stdin                     io.Reader
+	Stdout                    io.Writer // replaces the bounded stdout buffer, e.g. to stream tar
+	Timeout                   time.Duration
+	MaxOutput                 int
+}
+
+// execResult is the outcome of a command; ExitCode is the process exit code
+// (-1 when the command was cut off by the timeout).
+type execResult struct {
+	ExitCode        int    `json:"exitCode"`
+	Stdout          string `json:"stdout"`
+	Stderr          string `json:"stderr"`
+	StdoutTruncated bool   `json:"stdoutTruncated,omitempty"`
+	StderrTruncated bool   `json:"stderrTruncated,omitempty"`
+	TimedOut        bool   `json:"timedOut,omitempty"`
+	DurationMs      int64  `json:"durationMs"`
+}
+
+// execInPod runs a command and captures its output in bounded buffers.
+// A non-zero exit is a result, not an error; errors are reserved for the
+// stream failing to start or breaking.
+func execInPod(ctx context.Context, k *k8s.Clients, r execRequest) (*execResult, error) {
+	if len(r.Command) == 0 {
+		return nil, errors.New("command is required")
+	}
+	if r.Timeout <= 0 {
+		r.Timeout = defaultExecTimeout
+	}
+	if r.MaxOutput <= 0 {
+		r.MaxOutput = defaultExecOutput
+	}
+	opts := &corev1.PodExecOptions{Container: r.Container, Command: r.Command, Stdin: r.Stdin != nil, Stdout: true, Stderr: true}
+	executor, err := podExecutor(k, r.Namespace, r.Pod, opts)
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr := newBoundedBuffer(r.MaxOutput), newBoundedBuffer(r.MaxOutput)
+	execCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	start := time.Now()
+	var out io.Writer = stdout
+	if r.Stdout != nil {
+		out = r.Stdout
+	}
+	err = executor.StreamWithContext(execCtx, remotecommand.StreamOptions{Stdin: r.Stdin, Stdout: out, Stderr: stderr})
+	res := &execResult{DurationMs: time.Since(start).Milliseconds()}
+	res.Stdout, res.StdoutTruncated = stdout.result()
+	res.Stderr, res.StderrTruncated = stderr.result()
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		res.ExitCode = exitErr.ExitStatus()
+	case errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		res.ExitCode, res.TimedOut = -1, true
+	default:
+		return nil, fmt.Errorf("exec in %s/%s failed: %w", r.Namespace, r.Pod, err)
+	}
+	return res, nil
+}
+
+// enforceExec applies the exec policy and RBAC preflight to r, resolving the
+// target container (and its image) when the policy restricts images.
+func enforceExec(ctx context.Context, k *k8s.Clients, tool string, r *execRequest) error {
+	image := ""
+	if authz.ExecImagesRestricted() {
+		pod, err := k.Clientset.CoreV1().Pods(r.Namespace).Get(ctx, r.Pod, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if r.Container == "" {
+			r.Container = defaultContainer(pod)
+		}
+		image = containerImage(pod, r.Container)
+	}
+	if err := authz.EnforceExec(tool, r.Namespace, image, r.Command); err != nil {
+		return err
+	}
+	return authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "exec", Name: r.Pod, Namespace: r.Namespace})
+}
+
+// boundedBuffer keeps the first max bytes written and counts the rest, so a
+// chatty command cannot exhaust memory or the response size.
+type boundedBuffer struct {
+	mu      sync.Mutex
+	max     int
+	buf     bytes.Buffer
+	dropped int64
+}
+
+func newBoundedBuffer(limit int) *boundedBuffer { return &boundedBuffer{max: limit} }
+
+// Write never fails; failing would abort the remote command.
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	room := b.max - b.buf.Len()
+	if room > len(p) {
+		room = len(p)
+	}
+	if room > 0 {
+		b.buf.Write(p[:room])
+	}
+	b.dropped += int64(len(p) - max(room, 0))
+	return len(p), nil
+}
+
+// result returns the captured text with a marker when output was dropped.
+func (b *boundedBuffer) result() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped == 0 {
+		return b.buf.String(), false
+	}
+	s := strings.ToValidUTF8(b.buf.String(), "")
+	return s + fmt.Sprintf("\n...[truncated %d bytes]", b.dropped), true
+}
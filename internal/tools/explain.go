@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/openapi"
+)
+
+// explainField describes one schema field for resources-explain.
+type explainField struct {
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Enum        []any          `json:"enum,omitempty"`
+	Fields      []explainField `json:"fields,omitempty"`
+}
+
+// openAPIDoc is the components section of one group-version OpenAPI v3
+// document, decoded generically so CRD schemas work the same way.
+type openAPIDoc struct {
+	schemas map[string]map[string]any
+}
+
+func loadOpenAPIDoc(client openapi.Client, gv schema.GroupVersion) (*openAPIDoc, error) {
+	paths, err := client.Paths()
+	if err != nil {
+		return nil, err
+	}
+	key := "apis/" + gv.Group + "/" + gv.Version
+	if gv.Group == "" {
+		key = "api/" + gv.Version
+	}
+	gvDoc, ok := paths[key]
+	if !ok {
+		return nil, fmt.Errorf("the server publishes no OpenAPI v3 schema for %s", gv)
+	}
+	raw, err := gvDoc.Schema("application/json")
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("decode OpenAPI schema for %s: %w", gv, err)
+	}
+	return &openAPIDoc{schemas: doc.Components.Schemas}, nil
+}
+
+// kindSchema finds the top-level schema tagged with the GVK.
+func (d *openAPIDoc) kindSchema(gvk schema.GroupVersionKind) (map[string]any, error) {
+	for _, s := range d.schemas {
+		tags, _ := s["x-kubernetes-group-version-kind"].([]any)
+		for _, t := range tags {
+			m, _ := t.(map[string]any)
+			if m["group"] == gvk.Group && m["version"] == gvk.Version && m["kind"] == gvk.Kind {
+				return s, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("kind %s not found in the OpenAPI schema for %s", gvk.Kind, gvk.GroupVersion())
+}
+
+// resolve follows $ref (directly or through a single-entry allOf). The
+// referring schema's description wins since it is specific to the field.
+func (d *openAPIDoc) resolve(s map[string]any) (map[string]any, string) {
+	ref := refOf(s)
+	if ref == "" {
+		return s, ""
+	}
+	target, ok := d.schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	if !ok {
+		return s, ""
+	}
+	if _, ok := s["description"]; !ok {
+		return target, ref
+	}
+	merged := make(map[string]any, len(target)+1)
+	for k, v := range target {
+		merged[k] = v
+	}
+	merged["description"] = s["description"]
+	return merged, ref
+}
+
+func refOf(s map[string]any) string {
+	if ref, ok := s["$ref"].(string); ok {
+		return ref
+	}
+	if all, ok := s["allOf"].([]any); ok && len(all) == 1 {
+		if m, ok := all[0].(map[string]any); ok {
+			ref, _ := m["$ref"].(string)
+			return ref
+		}
+	}
+	return ""
+}
+
+// typeName renders a schema type the way kubectl explain does: a referenced
+// kind name, []T for arrays and map[string]T for maps.
+func (d *openAPIDoc) typeName(s map[string]any) string {
+	if ref := refOf(s); ref != "" {
+		name := ref[strings.LastIndex(ref, ".")+1:]
+		if target, ok := d.schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; ok {
+			if t, _ := target["type"].(string); t != "" && t != "object" {
+				return t
+			}
+		}
+		return name
+	}
+	if s["x-kubernetes-int-or-string"] == true {
+		return "IntOrString"
+	}
+	t, _ := s["type"].(string)
+	switch t {
+	case "array":
+		items, _ := s["items"].(map[string]any)
+		return "[]" + d.typeName(items)
+	case "object":
+		if ap, ok := s["additionalProperties"].(map[string]any); ok {
+			return "map[string]" + d.typeName(ap)
+		}
+		if _, ok := s["properties"]; !ok {
+			return "Object"
+		}
+	case "":
+		return "Object"
+	}
+	return t
+}
+
+// element steps through arrays and maps to the schema of their elements, so
+// paths like spec.containers.resources address the container type.
+func (d *openAPIDoc) element(s map[string]any) map[string]any {
+	for i := 0; i < 8; i++ {
+		s, _ = d.resolve(s)
+		if items, ok := s["items"].(map[string]any); ok {
+			s = items
+			continue
+		}
+		if ap, ok := s["additionalProperties"].(map[string]any); ok {
+			if _, props := s["properties"]; !props {
+				s = ap
+				continue
+			}
+		}
+		break
+	}
+	return s
+}
+
+// fields lists the properties of s, descending depth more levels. refs
+// tracks the schemas on the current branch so recursive types terminate.
+func (d *openAPIDoc) fields(s map[string]any, depth int, withDescriptions bool, refs map[string]bool) []explainField {
+	s = d.element(s)
+	props, _ := s["properties"].(map[string]any)
+	required := map[string]bool{}
+	if req, ok := s["required"].([]any); ok {
+		for _, r := range req {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]explainField, 0, len(names))
+	for _, name := range names {
+		ps, _ := props[name].(map[string]any)
+		f := explainField{Name: name, Type: d.typeName(ps), Required: required[name]}
+		resolved, _ := d.resolve(ps)
+		if withDescriptions {
+			f.Description, _ = resolved["description"].(string)
+		}
+		f.Enum, _ = d.element(ps)["enum"].([]any)
+		if depth > 1 {
+			ref := refOf(d.elementRef(ps))
+			if ref == "" || !refs[ref] {
+				if ref != "" {
+					refs[ref] = true
+				}
+				f.Fields = d.fields(ps, depth-1, withDescriptions, refs)
+				delete(refs, ref)
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// elementRef returns the (unresolved) element schema of arrays and maps so
+// its $ref can be used for cycle detection.
+func (d *openAPIDoc) elementRef(s map[string]any) map[string]any {
+	for i := 0; i < 8; i++ {
+		if items, ok := s["items"].(map[string]any); ok {
+			s = items
+			continue
+		}
+		if ap, ok := s["additionalProperties"].(map[string]any); ok {
+			s = ap
+			continue
+		}
+		break
+	}
+	return s
+}
+
+// explain walks fieldPath (dot separated, optionally prefixed with the kind)
+// from the kind's schema and describes the field and its children.
+func (d *openAPIDoc) explain(gvk schema.GroupVersionKind, fieldPath string, recursive bool, maxDepth int) (map[string]any, error) {
+	root, err := d.kindSchema(gvk)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, seg := range strings.Split(strings.Trim(fieldPath, "."), ".") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	if len(segments) > 0 && strings.EqualFold(segments[0], gvk.Kind) {
+		segments = segments[1:]
+	}
+	cur, typ, required, walked := root, gvk.Kind, false, []string{}
+	for _, seg := range segments {
+		parent := d.element(cur)
+		props, _ := parent["properties"].(map[string]any)
+		next, ok := props[seg].(map[string]any)
+		if !ok {
+			avail := make([]string, 0, len(props))
+			for name := range props {
+				avail = append(avail, name)
+			}
+			sort.Strings(avail)
+			at := gvk.Kind
+			if len(walked) > 0 {
+				at = strings.Join(walked, ".")
+			}
+			return nil, fmt.Errorf("field %q does not exist in %s; available fields: %s", seg, at, strings.Join(avail, ", "))
+		}
+		required = false
+		if req, ok := parent["required"].([]any); ok {
+			for _, r := range req {
+				if r == seg {
+					required = true
+				}
+			}
+		}
+		walked = append(walked, seg)
+		cur, typ = next, d.typeName(next)
+	}
+	resolved, _ := d.resolve(cur)
+	depth := 1
+	if recursive {
+		depth = maxDepth
+	}
+	out := map[string]any{
+		"group":   gvk.Group,
+		"version": gvk.Version,
+		"kind":    gvk.Kind,
+		"field":   strings.Join(walked, "."),
+		"type":    typ,
+	}
+	if desc, _ := resolved["description"].(string); desc != "" {
+		out["description"] = desc
+	}
+	if len(walked) > 0 {
+		out["required"] = required
+	}
+	if enum, ok := d.element(cur)["enum"].([]any); ok {
+		out["enum"] = enum
+	}
+	// kubectl explain --recursive prints names and types only; do the same to
+	// keep the output a manageable size
+	if fields := d.fields(cur, depth, !recursive, map[string]bool{}); len(fields) > 0 {
+		out["fields"] = fields
+	}
+	return out, nil
+}
@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+)
+
+// healthFinding is one observation from cluster-health. Severity is info,
+// warning or critical.
+type healthFinding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+const (
+	slowAPILatency   = time.Second
+	certWarnLeadTime = 30 * 24 * time.Hour
+)
+
+// controlPlaneComponents are the kube-system pods checked when the control
+// plane is self-hosted (kubeadm and similar); managed clusters hide them.
+var controlPlaneComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "etcd"}
+
+// assessClusterHealth runs the cluster-health checks. The overall status is
+// healthy only when no check found a warning or critical problem; it is
+// unknown when the live checks could not run at all.
+func assessClusterHealth(ctx context.Context, k *k8s.Clients) map[string]any {
+	var findings []healthFinding
+	add := func(severity, check, format string, args ...any) {
+		findings = append(findings, healthFinding{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+	out := map[string]any{"timestamp": time.Now().UTC().Format(time.RFC3339)}
+
+	start := time.Now()
+	ver := "unknown"
+	v, err := k.Discovery.ServerVersion()
+	latency := time.Since(start)
+	if err != nil {
+		add("critical", "api", "API server unreachable: %v", err)
+	} else if v != nil && v.GitVersion != "" {
+		ver = v.GitVersion
+	}
+	out["clusterVersion"] = ver
+	out["apiLatencyMs"] = latency.Milliseconds()
+	if err == nil && latency > slowAPILatency {
+		add("warning", "api", "API server answered in %dms (slower than %s)", latency.Milliseconds(), slowAPILatency)
+	}
+
+	live := k.SnapshotSource == "" && err == nil
+	if k.SnapshotSource != "" {
+		add("info", "backend", "serving an offline snapshot from %s; node and pod state is as captured, live checks skipped", k.SnapshotSource)
+	}
+	if live {
+		checks := map[string]any{}
+		for _, ep := range []string{"/readyz", "/livez"} {
+			failed, err := probeHealthEndpoint(ctx, k, ep)
+			name := strings.TrimPrefix(ep, "/")
+			switch {
+			case err != nil && failed == nil:
+				add("warning", name, "could not query %s: %v", ep, err)
+			case len(failed) > 0:
+				add("critical", name, "%s failing checks: %s", ep, strings.Join(failed, ", "))
+				checks[name] = map[string]any{"ok": false, "failed": failed}
+			default:
+				checks[name] = map[string]any{"ok": true}
+			}
+		}
+		out["checks"] = checks
+		certFindings(k.RestConfig, add)
+	}
+
+	if nodes, err := k.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err != nil {
+		add("warning", "nodes", "cannot list nodes: %v", err)
+	} else {
+		counts := map[string]int{"total": len(nodes.Items), "ready": 0, "notReady": 0, "unknown": 0}
+		var notReady, pressured []string
+		for i := range nodes.Items {
+			n := &nodes.Items[i]
+			switch strings.Split(nodeStatus(n), ",")[0] {
+			case "Ready":
+				counts["ready"]++
+			case "NotReady":
+				counts["notReady"]++
+				notReady = append(notReady, n.Name)
+			default:
+				counts["unknown"]++
+				notReady = append(notReady, n.Name)
+			}
+			if p := nodePressure(n); len(p) > 0 {
+				pressured = append(pressured, n.Name+" ("+strings.Join(p, ",")+")")
+			}
+		}
+		out["nodes"] = counts
+		switch {
+		case len(nodes.Items) > 0 && counts["ready"] == 0:
+			add("critical", "nodes", "no node is Ready (%d total)", len(nodes.Items))
+		case len(notReady) > 0:
+			add("warning", "nodes", "%d of %d nodes not Ready: %s", len(notReady), len(nodes.Items), strings.Join(notReady, ", "))
+		}
+		if len(pressured) > 0 {
+			add("warning", "nodes", "nodes under pressure: %s", strings.Join(pressured, ", "))
+		}
+	}
+
+	if pods, err := k.Clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{}); err != nil {
+		add("warning", "kube-system", "cannot list kube-system pods: %v", err)
+	} else {
+		systemPodFindings(pods.Items, add)
+	}
+
+	status := "healthy"
+	for _, f := range findings {
+		switch f.Severity {
+		case "critical":
+			status = "unhealthy"
+		case "warning":
+			if status == "healthy" {
+				status = "degraded"
+			}
+		}
+	}
+	if !live && status == "healthy" {
+		status = "unknown"
+	}
+	out["status"] = status
+	if findings == nil {
+		findings = []healthFinding{}
+	}
+	out["findings"] = findings
+	return out
+}
+
+// probeHealthEndpoint queries /readyz or /livez in verbose mode and returns
+// the names of failing checks. A non-2xx answer still carries the verbose
+// body, so failures are parsed from it as well.
+func probeHealthEndpoint(ctx context.Context, k *k8s.Clients, path string) ([]string, error) {
+	rc := k.Discovery.RESTClient()
+	if rc == nil {
+		return nil, fmt.Errorf("health endpoints not available on this backend")
+	}
+	body, err := rc.Get().AbsPath(path).Param("verbose", "").Do(ctx).Raw()
+	failed := []string{}
+	for _, line := range strings.Split(string(body), "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "[-]")
+		if f := strings.Fields(rest); ok && len(f) > 0 {
+			failed = append(failed, f[0])
+		}
+	}
+	if err != nil && len(failed) == 0 {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// systemPodFindings checks self-hosted control-plane components and CoreDNS.
+func systemPodFindings(pods []corev1.Pod, add func(severity, check, format string, args ...any)) {
+	seen := map[string]bool{}
+	dnsTotal, dnsReady := 0, 0
+	for i := range pods {
+		pod := &pods[i]
+		ready := podReady(pod)
+		if pod.Labels["k8s-app"] == "kube-dns" {
+			dnsTotal++
+			if ready {
+				dnsReady++
+			}
+			continue
+		}
+		comp := pod.Labels["component"]
+		for _, c := range controlPlaneComponents {
+			if comp == c {
+				seen[c] = true
+				if !ready {
+					add("critical", "control-plane", "%s pod %s is not ready (phase %s)", c, pod.Name, pod.Status.Phase)
+				}
+			}
+		}
+	}
+	if len(seen) == 0 {
+		add("info", "control-plane", "no control-plane pods in kube-system (managed control plane); relying on /readyz")
+	}
+	switch {
+	case dnsTotal == 0:
+		add("warning", "coredns", "no CoreDNS pods found in kube-system (label k8s-app=kube-dns)")
+	case dnsReady == 0:
+		add("critical", "coredns", "none of %d CoreDNS pods are ready; in-cluster DNS is down", dnsTotal)
+	case dnsReady < dnsTotal:
+		add("warning", "coredns", "%d of %d CoreDNS pods ready", dnsReady, dnsTotal)
+	}
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// certFindings reports expired or soon-expiring certificates for the API
+// server endpoint and the kubeconfig client certificate.
+func certFindings(cfg *rest.Config, add func(severity, check, format string, args ...any)) {
+	if cfg == nil {
+		return
+	}
+	check := func(what string, cert *x509.Certificate) {
+		left := time.Until(cert.NotAfter)
+		switch {
+		case left <= 0:
+			add("critical", "certificates", "%s certificate expired on %s", what, cert.NotAfter.UTC().Format(time.RFC3339))
+		case left < certWarnLeadTime:
+			add("warning", "certificates", "%s certificate expires in %d days (%s)", what, int(left.Hours()/24), cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+	certData := cfg.TLSClientConfig.CertData
+	if len(certData) == 0 && cfg.TLSClientConfig.CertFile != "" {
+		certData, _ = os.ReadFile(cfg.TLSClientConfig.CertFile)
+	}
+	if block, _ := pem.Decode(certData); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			check("client", cert)
+		}
+	}
+	u, err := url.Parse(cfg.Host)
+	if err != nil || u.Scheme != "https" {
+		return
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+	// The handshake only fetches the certificate to read its dates, so it
+	// skips verification: an expired certificate would otherwise fail it
+	// and never be reported. Nothing is sent over this connection.
+	serverName := cfg.TLSClientConfig.ServerName
+	if serverName == "" {
+		serverName = u.Hostname()
+	}
+	tlsCfg := &tls.Config{ServerName: serverName, InsecureSkipVerify: true}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", host, tlsCfg)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+		check("API server "+u.Host, certs[0])
+	}
+}
@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxTopErrors     = 10
+	maxErrorMsgBytes = 200
+)
+
+// Well-known keys of common JSON loggers (zap, logrus, slog, pino, bunyan,
+// ECS, Python's json logger).
+var (
+	levelKeys   = []string{"level", "lvl", "severity", "log.level", "levelname"}
+	messageKeys = []string{"msg", "message", "error", "err"}
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+)
+
+// jsonLogQuery filters JSON log records on fields and projects them.
+type jsonLogQuery struct {
+	where  []fieldCond
+	fields []string
+}
+
+// fieldCond is one where clause: field=value, field!=value (both case
+// insensitive) or field~regex. Fields are dotted paths into the record.
+type fieldCond struct {
+	path, op, value string
+	re              *regexp.Regexp
+}
+
+func newJSONLogQuery(where, fields []string) (*jsonLogQuery, error) {
+	q := &jsonLogQuery{fields: fields}
+	for _, w := range where {
+		c, err := parseFieldCond(w)
+		if err != nil {
+			return nil, err
+		}
+		q.where = append(q.where, c)
+	}
+	return q, nil
+}
+
+func parseFieldCond(s string) (fieldCond, error) {
+	i := strings.IndexAny(s, "!=~")
+	if i <= 0 {
+		return fieldCond{}, fmt.Errorf("invalid where %q (want field=value, field!=value or field~regex)", s)
+	}
+	c := fieldCond{path: strings.TrimSpace(s[:i]), op: s[i : i+1]}
+	if c.op == "!" {
+		if !strings.HasPrefix(s[i:], "!=") {
+			return fieldCond{}, fmt.Errorf("invalid where %q (want field=value, field!=value or field~regex)", s)
+		}
+		c.op = "!="
+	}
+	c.value = strings.TrimSpace(s[i+len(c.op):])
+	if c.op == "~" {
+		re, err := regexp.Compile(c.value)
+		if err != nil {
+			return fieldCond{}, fmt.Errorf("invalid where %q: %w", s, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func (c fieldCond) match(rec map[string]any) bool {
+	v, ok := lookupField(rec, c.path)
+	s := fieldString(v)
+	switch c.op {
+	case "=":
+		return ok && strings.EqualFold(s, c.value)
+	case "!=":
+		return !ok || !strings.EqualFold(s, c.value)
+	}
+	return ok && c.re.MatchString(s)
+}
+
+func (q *jsonLogQuery) matches(rec map[string]any) bool {
+	for _, c := range q.where {
+		if !c.match(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// project keeps the requested fields, keyed by their path; all of them when
+// no fields were requested.
+func (q *jsonLogQuery) project(rec map[string]any) map[string]any {
+	if len(q.fields) == 0 {
+		return rec
+	}
+	out := make(map[string]any, len(q.fields))
+	for _, f := range q.fields {
+		if v, ok := lookupField(rec, f); ok {
+			out[f] = v
+		}
+	}
+	return out
+}
+
+// parseJSONLine returns the JSON object on a log line, or nil if there is none.
+func parseJSONLine(line string) map[string]any {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return nil
+	}
+	var rec map[string]any
+	if json.Unmarshal([]byte(line), &rec) != nil {
+		return nil
+	}
+	return rec
+}
+
+// lookupField resolves a dotted path; a literal key containing dots (as in
+// ECS "log.level") wins over nesting.
+func lookupField(rec map[string]any, path string) (any, bool) {
+	if v, ok := rec[path]; ok {
+		return v, true
+	}
+	var cur any = rec
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func fieldString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func firstField(rec map[string]any, keys []string) string {
+	for _, k := range keys {
+		if v, ok := lookupField(rec, k); ok {
+			return fieldString(v)
+		}
+	}
+	return ""
+}
+
+// recordLevel returns the lower-cased level; numeric pino/bunyan levels are
+// mapped to their names.
+func recordLevel(rec map[string]any) string {
+	for _, k := range levelKeys {
+		v, ok := lookupField(rec, k)
+		if !ok {
+			continue
+		}
+		if n, ok := v.(float64); ok {
+			switch {
+			case n >= 60:
+				return "fatal"
+			case n >= 50:
+				return "error"
+			case n >= 40:
+				return "warn"
+			case n >= 30:
+				return "info"
+			case n >= 20:
+				return "debug"
+			}
+			return "trace"
+		}
+		return strings.ToLower(fieldString(v))
+	}
+	return ""
+}
+
+func isErrorLevel(level string) bool {
+	switch level {
+	case "error", "err", "fatal", "panic", "dpanic", "critical", "crit", "alert", "emergency", "emerg":
+		return true
+	}
+	return false
+}
+
+// jsonLogSummary aggregates the JSON records a query matched.
+type jsonLogSummary struct {
+	Parsed    int            `json:"parsed"`
+	Unparsed  int            `json:"unparsed"`
+	Matched   int            `json:"matched"`
+	Levels    map[string]int `json:"levels,omitempty"`
+	TopErrors []errorMessage `json:"topErrors,omitempty"`
+	First     string         `json:"first,omitempty"`
+	Last      string         `json:"last,omitempty"`
+	errors    map[string]*errorMessage
+}
+
+// errorMessage counts one distinct error message.
+type errorMessage struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+	First   string `json:"first,omitempty"`
+	Last    string `json:"last,omitempty"`
+}
+
+// add counts a matched record; records must be added in log order. stamp is
+// the kubelet timestamp, if known, else the record's own time field is used.
+func (s *jsonLogSummary) add(rec map[string]any, stamp string) {
+	if s.Levels == nil {
+		s.Levels, s.errors = map[string]int{}, map[string]*errorMessage{}
+	}
+	s.Matched++
+	if stamp == "" {
+		stamp = firstField(rec, timeKeys)
+	}
+	if s.First == "" {
+		s.First = stamp
+	}
+	if stamp != "" {
+		s.Last = stamp
+	}
+	level := recordLevel(rec)
+	if level == "" {
+		level = "unknown"
+	}
+	s.Levels[level]++
+	if !isErrorLevel(level) {
+		return
+	}
+	msg := strings.ToValidUTF8(firstField(rec, messageKeys), "")
+	if len(msg) > maxErrorMsgBytes {
+		msg = strings.ToValidUTF8(msg[:maxErrorMsgBytes], "") + "…"
+	}
+	e := s.errors[msg]
+	if e == nil {
+		e = &errorMessage{Message: msg, First: stamp}
+		s.errors[msg] = e
+	}
+	e.Count++
+	e.Last = stamp
+}
+
+// finish ranks the error messages by frequency.
+func (s *jsonLogSummary) finish() *jsonLogSummary {
+	s.TopErrors = s.TopErrors[:0]
+	for _, e := range s.errors {
+		s.TopErrors = append(s.TopErrors, *e)
+	}
+	sort.Slice(s.TopErrors, func(i, j int) bool {
+		if s.TopErrors[i].Count != s.TopErrors[j].Count {
+			return s.TopErrors[i].Count > s.TopErrors[j].Count
+		}
+		return s.TopErrors[i].Message < s.TopErrors[j].Message
+	})
+	if len(s.TopErrors) > maxTopErrors {
+		s.TopErrors = s.TopErrors[:maxTopErrors]
+	}
+	return s
+}
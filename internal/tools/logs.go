@@ -0,0 +1,700 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+const (
+	// maxLogLines caps the lines returned per container.
+	maxLogLines = 1000
+	// maxScanLines is how far back a filtered query reads when the caller
+	// gave no tailLines; filtering happens here, not in the API server.
+	maxScanLines  = 20000
+	maxLogContext = 20
+	// searchSourceBytes and searchTotalBytes bound what logs-search reads:
+	// per container unless limitBytes is smaller, and over all containers.
+	searchSourceBytes = 4 << 20
+	searchTotalBytes  = 64 << 20
+)
+
+// logParams are the log options shared by the pod log tools.
+type logParams struct {
+	Previous                            bool
+	TailLines, SinceSeconds, LimitBytes *int64
+	SinceTime                           string // RFC3339
+	Timestamps                          *bool
+	Include, Exclude                    string // regular expressions
+	Context                             int    // lines around each include match
+	// JSON parses lines as JSON objects; Where (field=value, field!=value,
+	// field~regex) and Fields (projection) imply it.
+	JSON          bool
+	Where, Fields []string
+}
+
+// containerLogs is the (possibly filtered) log of one container.
+type containerLogs struct {
+	Container   string   `json:"container,omitempty"`
+	Init        bool     `json:"init,omitempty"`
+	Lines       int      `json:"lines"`
+	Truncated   bool     `json:"truncated,omitempty"`
+	TruncatedBy []string `json:"truncatedBy,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Logs        string   `json:"logs,omitempty"`
+	// JSON mode
+	Records []map[string]any `json:"records,omitempty"`
+	Summary *jsonLogSummary  `json:"summary,omitempty"`
+}
+
+// logQuery is a validated log request: the options sent to the API server
+// plus the filtering applied to what comes back.
+type logQuery struct {
+	opts corev1.PodLogOptions
+	scan int // implicit tail; 0 when the caller chose tailLines
+	// until drops lines stamped after it before the tail is applied
+	// (logs-search, which reads with timestamps)
+	until  time.Time
+	filter logFilter
+	json   *jsonLogQuery // nil unless JSON mode
+}
+
+func newLogQuery(p logParams) (*logQuery, error) {
+	filter, err := newLogFilter(p.Include, p.Exclude, p.Context)
+	if err != nil {
+		return nil, err
+	}
+	q := &logQuery{filter: filter, opts: corev1.PodLogOptions{
+		Previous:     p.Previous,
+		TailLines:    p.TailLines,
+		SinceSeconds: p.SinceSeconds,
+		LimitBytes:   p.LimitBytes,
+		Timestamps:   p.Timestamps != nil && *p.Timestamps,
+	}}
+	if p.JSON || len(p.Where) > 0 || len(p.Fields) > 0 {
+		if q.json, err = newJSONLogQuery(p.Where, p.Fields); err != nil {
+			return nil, err
+		}
+	}
+	if p.SinceTime != "" {
+		if p.SinceSeconds != nil {
+			return nil, errors.New("sinceSeconds and sinceTime are mutually exclusive")
+		}
+		t, err := time.Parse(time.RFC3339, p.SinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime %q (want RFC3339, e.g. 2024-05-01T12:00:00Z)", p.SinceTime)
+		}
+		q.opts.SinceTime = &metav1.Time{Time: t}
+	}
+	if p.LimitBytes != nil && *p.LimitBytes <= 0 {
+		return nil, errors.New("limitBytes must be positive")
+	}
+	if p.TailLines == nil {
+		// ask for one line more than we keep so truncation is detectable
+		q.scan = maxLogLines
+		if filter.active() || q.json != nil {
+			q.scan = maxScanLines
+		}
+		tail := int64(q.scan + 1)
+		q.opts.TailLines = &tail
+	}
+	return q, nil
+}
+
+// read returns one container's log lines after the byte and line caps,
+// together with what cut them short.
+func (q *logQuery) read(ctx context.Context, k *k8s.Clients, namespace, pod, container string) ([]string, []string, error) {
+	opts := q.opts
+	opts.Container = container
+	text, err := k.PodLogs(ctx, namespace, pod, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, truncatedBy := q.trim(text)
+	return lines, truncatedBy, nil
+}
+
+// trim splits a fetched log into lines: a partial last line left by
+// limitBytes is dropped, lines after until are cut and then only the last
+// scan lines are kept.
+func (q *logQuery) trim(text string) ([]string, []string) {
+	var truncatedBy []string
+	if q.opts.LimitBytes != nil && int64(len(text)) >= *q.opts.LimitBytes {
+		// the server cut the log mid-stream; drop the partial last line
+		if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+			text = text[:i+1]
+		}
+		truncatedBy = append(truncatedBy, "limitBytes")
+	}
+	lines := splitLogLines(text)
+	if !q.until.IsZero() {
+		for i, l := range lines {
+			// unstamped lines (e.g. a wrapped write) go with the line before
+			if stamp, _ := splitTimestamp(l); stamp != "" {
+				if ts, _ := time.Parse(time.RFC3339Nano, stamp); ts.After(q.until) {
+					lines = lines[:i]
+					break
+				}
+			}
+		}
+	}
+	if q.scan > 0 && len(lines) > q.scan {
+		lines = lines[len(lines)-q.scan:]
+		truncatedBy = append(truncatedBy, "tailLines")
+	}
+	return lines, truncatedBy
+}
+
+// fetch reads one container's log and applies the filter and line caps.
+func (q *logQuery) fetch(ctx context.Context, k *k8s.Clients, namespace, pod, container string) (containerLogs, error) {
+	cl := containerLogs{Container: container}
+	lines, truncatedBy, err := q.read(ctx, k, namespace, pod, container)
+	if err != nil {
+		return cl, err
+	}
+	cl.TruncatedBy = truncatedBy
+	if q.json != nil {
+		q.fetchJSON(&cl, lines)
+		return cl, nil
+	}
+	lines = q.filter.apply(lines)
+	if len(lines) > maxLogLines {
+		lines = lines[len(lines)-maxLogLines:]
+		cl.TruncatedBy = append(cl.TruncatedBy, "maxLines")
+	}
+	cl.Truncated = len(cl.TruncatedBy) > 0
+	cl.Lines = len(lines)
+	cl.Logs = strings.Join(lines, "\n")
+	return cl, nil
+}
+
+// fetchJSON fills cl with the matching JSON records of lines and their summary.
+func (q *logQuery) fetchJSON(cl *containerLogs, lines []string) {
+	sum := &jsonLogSummary{}
+	for _, i := range q.filter.selectLines(lines) {
+		stamp, line := "", lines[i]
+		if q.opts.Timestamps {
+			stamp, line = splitTimestamp(line)
+		}
+		rec := parseJSONLine(line)
+		if rec == nil {
+			sum.Unparsed++
+			continue
+		}
+		sum.Parsed++
+		if !q.json.matches(rec) {
+			continue
+		}
+		sum.add(rec, stamp)
+		cl.Records = append(cl.Records, q.json.project(rec))
+	}
+	if len(cl.Records) > maxLogLines {
+		cl.Records = cl.Records[len(cl.Records)-maxLogLines:]
+		cl.TruncatedBy = append(cl.TruncatedBy, "maxLines")
+	}
+	cl.Truncated = len(cl.TruncatedBy) > 0
+	cl.Lines = len(cl.Records)
+	cl.Summary = sum.finish()
+}
+
+// keepsJSON reports whether a streamed line passes the JSON conditions.
+func (q *logQuery) keepsJSON(line string) bool {
+	if q.json == nil {
+		return true
+	}
+	if q.opts.Timestamps {
+		_, line = splitTimestamp(line)
+	}
+	rec := parseJSONLine(line)
+	return rec != nil && q.json.matches(rec)
+}
+
+// splitTimestamp splits the RFC3339 timestamp the kubelet prefixes to each
+// line when asked to; stamp is empty when the line has none.
+func splitTimestamp(line string) (stamp, rest string) {
+	if s, r, ok := strings.Cut(line, " "); ok {
+		if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return s, r
+		}
+	}
+	return "", line
+}
+
+func splitLogLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// logFilter is a grep over log lines: exclude drops lines first, then only
+// lines matching include are kept, with context lines around each match.
+type logFilter struct {
+	include, exclude *regexp.Regexp
+	context          int
+}
+
+func newLogFilter(include, exclude string, context int) (logFilter, error) {
+	var f logFilter
+	var err error
+	if include != "" {
+		if f.include, err = regexp.Compile(include); err != nil {
+			return f, fmt.Errorf("invalid include pattern: %w", err)
+		}
+	}
+	if exclude != "" {
+		if f.exclude, err = regexp.Compile(exclude); err != nil {
+			return f, fmt.Errorf("invalid exclude pattern: %w", err)
+		}
+	}
+	f.context = min(max(context, 0), maxLogContext)
+	return f, nil
+}
+
+func (f logFilter) active() bool { return f.include != nil || f.exclude != nil }
+
+// keeps reports whether a single line passes the filter, ignoring context.
+func (f logFilter) keeps(l string) bool {
+	return (f.exclude == nil || !f.exclude.MatchString(l)) && (f.include == nil || f.include.MatchString(l))
+}
+
+// apply filters lines; like grep -C, non-adjacent groups are separated by "--".
+func (f logFilter) apply(lines []string) []string {
+	var out []string
+	last := -1
+	for _, i := range f.selectLines(lines) {
+		if f.include != nil && f.context > 0 && last >= 0 && i > last+1 {
+			out = append(out, "--")
+		}
+		out = append(out, lines[i])
+		last = i
+	}
+	return out
+}
+
+// selectLines returns the indexes of the lines the filter keeps, in order.
+func (f logFilter) selectLines(lines []string) []int {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if f.exclude != nil && f.exclude.MatchString(l) {
+			continue
+		}
+		if f.include == nil {
+			keep[i] = true
+		} else if f.include.MatchString(l) {
+			for j := max(i-f.context, 0); j <= min(i+f.context, len(lines)-1); j++ {
+				keep[j] = true
+			}
+		}
+	}
+	var out []int
+	for i, k := range keep {
+		// excluded lines never appear, not even as context
+		if k && (f.exclude == nil || !f.exclude.MatchString(lines[i])) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+const (
+	defaultSearchConcurrency = 5
+	maxSearchConcurrency     = 10
+	maxSearchPods            = 100
+)
+
+// logLine is one line of a multi-pod log search.
+type logLine struct {
+	Time      string         `json:"time,omitempty"`
+	Pod       string         `json:"pod"`
+	Container string         `json:"container"`
+	Message   string         `json:"message,omitempty"`
+	Fields    map[string]any `json:"fields,omitempty"` // JSON mode
+	ts        time.Time
+	rec       map[string]any
+}
+
+// logSource is one container whose log a search reads.
+type logSource struct{ pod, container string }
+
+func RegisterLogs(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "logs-search", Description: "Search and merge logs from all pods matching a selector or workload, ordered by timestamp", DirectResult: true, Handler: notReady})
+		return
+	}
+	// logs-search
+	reg.Register(mcp.Tool{
+		Name:         "logs-search",
+		Description:  "Search and merge logs from all pods matching a selector or workload, ordered by timestamp",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("logs-search", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace, LabelSelector string
+				Kind, Name               string // workload, e.g. Deployment/web
+				Container                string // only this container in each pod
+				Until                    string // RFC3339 end of the time window
+				Concurrency              int
+				logParams
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			var until time.Time
+			if p.Until != "" {
+				t, err := time.Parse(time.RFC3339, p.Until)
+				if err != nil {
+					return nil, fmt.Errorf("invalid until %q (want RFC3339)", p.Until)
+				}
+				until = t
+			}
+			q, err := newLogQuery(p.logParams)
+			if err != nil {
+				return nil, err
+			}
+			// merging needs the kubelet timestamps; filters then see only the message
+			q.opts.Timestamps = true
+			if !until.IsZero() {
+				// the server's tail would end now, not at until: read forward
+				// from since instead and take the tail below until
+				q.until = until
+				if q.scan > 0 {
+					q.opts.TailLines = nil
+				}
+			}
+			sel, err := podSelector(ctx, k, p.Namespace, p.Kind, p.Name, p.LabelSelector)
+			if err != nil {
+				return nil, err
+			}
+			pods, err := k.Clientset.CoreV1().Pods(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+			if err != nil {
+				return nil, err
+			}
+			sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+			out := map[string]any{"namespace": p.Namespace, "selector": sel.String(), "pods": len(pods.Items)}
+			var truncatedBy []string
+			if len(pods.Items) > maxSearchPods {
+				pods.Items = pods.Items[:maxSearchPods]
+				truncatedBy = append(truncatedBy, "maxPods")
+			}
+			var sources []logSource
+			for _, pod := range pods.Items {
+				for _, c := range pod.Spec.Containers {
+					if p.Container == "" || c.Name == p.Container {
+						sources = append(sources, logSource{pod.Name, c.Name})
+					}
+				}
+			}
+			// bound memory: every source shares searchTotalBytes
+			limit := int64(min(searchSourceBytes, searchTotalBytes/max(len(sources), 1)))
+			if q.opts.LimitBytes == nil || *q.opts.LimitBytes > limit {
+				q.opts.LimitBytes = &limit
+			}
+			concurrency := p.Concurrency
+			if concurrency <= 0 {
+				concurrency = defaultSearchConcurrency
+			}
+			concurrency = min(concurrency, maxSearchConcurrency)
+
+			results := make([][]logLine, len(sources))
+			failures := make([]string, len(sources))
+			cut := make([][]string, len(sources))
+			counts := make([]jsonLogSummary, len(sources))
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			sem := make(chan struct{}, concurrency)
+			done := 0
+			for i, src := range sources {
+				wg.Add(1)
+				go func(i int, src logSource) {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					raw, by, err := q.read(ctx, k, p.Namespace, src.pod, src.container)
+					if err != nil {
+						failures[i] = err.Error()
+					} else {
+						results[i], cut[i] = q.searchLines(raw, src, &counts[i]), by
+					}
+					mu.Lock()
+					done++
+					mcp.ReportProgress(ctx, float64(done), float64(len(sources)), src.pod+"/"+src.container)
+					mu.Unlock()
+				}(i, src)
+			}
+			wg.Wait()
+
+			var lines []logLine
+			var errs []map[string]string
+			var truncatedSources []string
+			for i, src := range sources {
+				lines = append(lines, results[i]...)
+				if failures[i] != "" {
+					errs = append(errs, map[string]string{"pod": src.pod, "container": src.container, "error": failures[i]})
+				}
+				if len(cut[i]) > 0 {
+					truncatedSources = append(truncatedSources, src.pod+"/"+src.container)
+				}
+			}
+			sort.SliceStable(lines, func(i, j int) bool { return lines[i].ts.Before(lines[j].ts) })
+			out["sources"], out["matched"] = len(sources), len(lines)
+			if q.json != nil {
+				sum := &jsonLogSummary{}
+				for _, c := range counts {
+					sum.Parsed += c.Parsed
+					sum.Unparsed += c.Unparsed
+				}
+				for _, l := range lines {
+					sum.add(l.rec, l.Time)
+				}
+				out["summary"] = sum.finish()
+			}
+			if len(lines) > maxLogLines {
+				lines = lines[len(lines)-maxLogLines:]
+				truncatedBy = append(truncatedBy, "maxLines")
+			}
+			if len(truncatedSources) > 0 {
+				truncatedBy = append(truncatedBy, "sources")
+				out["truncatedSources"] = truncatedSources
+			}
+			if len(errs) > 0 {
+				out["errors"] = errs
+			}
+			out["lines"] = lines
+			out["truncated"] = len(truncatedBy) > 0
+			if len(truncatedBy) > 0 {
+				out["truncatedBy"] = truncatedBy
+			}
+			return out, nil
+		}),
+	})
+}
+
+// searchLines splits timestamped log lines and applies the filter to the
+// messages. Context separators are not emitted:
+// merged output interleaves sources, so adjacency has no meaning there. In
+// JSON mode the parsed and unparsed lines are counted into counts.
+func (q *logQuery) searchLines(raw []string, src logSource, counts *jsonLogSummary) []logLine {
+	lines := make([]logLine, 0, len(raw))
+	var last time.Time
+	for _, l := range raw {
+		line := logLine{Pod: src.pod, Container: src.container, Message: l, ts: last}
+		if stamp, msg := splitTimestamp(l); stamp != "" {
+			line.ts, _ = time.Parse(time.RFC3339Nano, stamp)
+			line.Time, line.Message = stamp, msg
+		}
+		// unstamped lines (e.g. a wrapped write) sort with the line before
+		last = line.ts
+		lines = append(lines, line)
+	}
+	msgs := make([]string, len(lines))
+	for i := range lines {
+		msgs[i] = lines[i].Message
+	}
+	var out []logLine
+	for _, i := range q.filter.selectLines(msgs) {
+		line := lines[i]
+		if q.json != nil {
+			if line.rec = parseJSONLine(line.Message); line.rec == nil {
+				counts.Unparsed++
+				continue
+			}
+			counts.Parsed++
+			if !q.json.matches(line.rec) {
+				continue
+			}
+			line.Message, line.Fields = "", q.json.project(line.rec)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// podSelector resolves the pods of a workload (Deployment, StatefulSet,
+// DaemonSet, ReplicaSet, Job or Service), narrowed by an optional label
+// selector.
+func podSelector(ctx context.Context, k *k8s.Clients, namespace, kind, name, labelSelector string) (labels.Selector, error) {
+	sel := labels.Everything()
+	if labelSelector != "" {
+		var err error
+		if sel, err = labels.Parse(labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid labelSelector: %w", err)
+		}
+	}
+	if kind == "" {
+		if labelSelector == "" {
+			return nil, errors.New("labelSelector or kind and name are required")
+		}
+		return sel, nil
+	}
+	if name == "" {
+		return nil, errors.New("name is required with kind")
+	}
+	var ls *metav1.LabelSelector
+	switch canonicalKind(kind) {
+	case "Deployment":
+		obj, err := k.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ls = obj.Spec.Selector
+	case "StatefulSet":
+		obj, err := k.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ls = obj.Spec.Selector
+	case "DaemonSet":
+		obj, err := k.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ls = obj.Spec.Selector
+	case "ReplicaSet":
+		obj, err := k.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ls = obj.Spec.Selector
+	case "Job":
+		obj, err := k.Clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ls = obj.Spec.Selector
+	case "Service":
+		obj, err := k.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if len(obj.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s has no selector", name)
+		}
+		ls = &metav1.LabelSelector{MatchLabels: obj.Spec.Selector}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q (use Deployment, StatefulSet, DaemonSet, ReplicaSet, Job or Service)", kind)
+	}
+	ws, err := metav1.LabelSelectorAsSelector(ls)
+	if err != nil {
+		return nil, err
+	}
+	if ws.Empty() {
+		return nil, fmt.Errorf("%s %s has an empty selector", kind, name)
+	}
+	reqs, _ := sel.Requirements()
+	return ws.Add(reqs...), nil
+}
+
+const (
+	defaultFollowDuration = time.Minute
+	maxFollowDuration     = 10 * time.Minute
+)
+
+// followResult is the outcome of following a container's log.
+type followResult struct {
+	containerLogs
+	// StoppedBy is duration, lines, pattern, ended (the container exited or
+	// the stream closed) or cancelled.
+	StoppedBy   string `json:"stoppedBy"`
+	MatchedLine string `json:"matchedLine,omitempty"`
+	DurationMs  int64  `json:"durationMs"`
+}
+
+// follow streams one container's log, sending each kept line to the client
+// as a progress notification (a log message without a progress token), until
+// d elapses, maxLines lines were kept, a line matches stop, the stream ends
+// or ctx is cancelled. Include and exclude apply per line; context lines do
+// not.
+func (q *logQuery) follow(ctx context.Context, k *k8s.Clients, namespace, pod, container string, d time.Duration, maxLines int, stop *regexp.Regexp) (*followResult, error) {
+	opts := q.opts
+	opts.Container, opts.Follow = container, true
+	if q.scan > 0 {
+		// without an explicit tailLines or since, only new lines are wanted
+		if opts.SinceSeconds == nil && opts.SinceTime == nil {
+			opts.TailLines = new(int64)
+		} else {
+			opts.TailLines = nil
+		}
+	}
+	followCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	start := time.Now()
+	stream, err := k.PodLogStream(followCtx, namespace, pod, &opts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	res := &followResult{containerLogs: containerLogs{Container: container}}
+	var lines []string
+	sc := bufio.NewScanner(stream)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		l := sc.Text()
+		matched := stop != nil && stop.MatchString(l)
+		if matched || (q.filter.keeps(l) && q.keepsJSON(l)) {
+			lines = append(lines, l)
+			mcp.ReportLine(ctx, float64(len(lines)), float64(maxLines), l)
+		}
+		if matched {
+			res.StoppedBy, res.MatchedLine = "pattern", l
+			break
+		}
+		if len(lines) >= maxLines {
+			res.StoppedBy = "lines"
+			break
+		}
+	}
+	if res.StoppedBy == "" {
+		switch {
+		case ctx.Err() != nil:
+			res.StoppedBy = "cancelled"
+		case followCtx.Err() != nil:
+			res.StoppedBy = "duration"
+		case sc.Err() != nil:
+			res.StoppedBy, res.Error = "error", sc.Err().Error()
+		default:
+			res.StoppedBy = "ended"
+		}
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.Lines = len(lines)
+	res.Logs = strings.Join(lines, "\n")
+	return res, nil
+}
+
+// kindAliases maps the kubectl spellings of workload kinds to the kind.
+var kindAliases = map[string]string{
+	"deployment": "Deployment", "deployments": "Deployment", "deploy": "Deployment",
+	"statefulset": "StatefulSet", "statefulsets": "StatefulSet", "sts": "StatefulSet",
+	"daemonset": "DaemonSet", "daemonsets": "DaemonSet", "ds": "DaemonSet",
+	"replicaset": "ReplicaSet", "replicasets": "ReplicaSet", "rs": "ReplicaSet",
+	"job": "Job", "jobs": "Job",
+	"cronjob": "CronJob", "cronjobs": "CronJob", "cj": "CronJob",
+	"service": "Service", "services": "Service", "svc": "Service",
+}
+
+// canonicalKind returns the kind for a kubectl-style spelling, or "".
+func canonicalKind(kind string) string { return kindAliases[strings.ToLower(kind)] }
@@ -0,0 +1,286 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+var (
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+// errNoMetrics is returned when the resource metrics API is not served.
+var errNoMetrics = errors.New("the metrics.k8s.io API is not available: metrics-server does not appear to be installed (see https://github.com/kubernetes-sigs/metrics-server)")
+
+// containerUsage is CPU/memory usage of one container compared with its spec.
+type containerUsage struct {
+	Name            string `json:"name"`
+	CPU             string `json:"cpu"`
+	Memory          string `json:"memory"`
+	CPURequest      string `json:"cpuRequest,omitempty"`
+	CPULimit        string `json:"cpuLimit,omitempty"`
+	MemoryRequest   string `json:"memoryRequest,omitempty"`
+	MemoryLimit     string `json:"memoryLimit,omitempty"`
+	CPUPercentOfReq int64  `json:"cpuPercentOfRequest,omitempty"`
+	CPUPercentOfLim int64  `json:"cpuPercentOfLimit,omitempty"`
+	MemPercentOfReq int64  `json:"memoryPercentOfRequest,omitempty"`
+	MemPercentOfLim int64  `json:"memoryPercentOfLimit,omitempty"`
+	cpu, mem        resource.Quantity
+}
+
+func RegisterMetrics(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "top-pods", Description: "Show pod and container CPU/memory usage from metrics-server against requests and limits", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "top-nodes", Description: "Show node CPU/memory usage from metrics-server against allocatable", DirectResult: true, Handler: notReady})
+		return
+	}
+	// top-pods
+	reg.Register(mcp.Tool{
+		Name:         "top-pods",
+		Description:  "Show pod and container CPU/memory usage from metrics-server against requests and limits",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("top-pods", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace     string
+				AllNamespaces bool
+				LabelSelector string
+				SortBy        string // cpu or memory (default)
+				Limit         int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			ns := p.Namespace
+			if p.AllNamespaces {
+				ns = ""
+			} else if ns == "" {
+				ns = k.DefaultNamespace
+			}
+			sortBy, err := metricsSortKey(p.SortBy)
+			if err != nil {
+				return nil, err
+			}
+			if p.Limit <= 0 {
+				p.Limit = 20
+			}
+			list, err := listMetrics(ctx, k, podMetricsGVR, ns, p.LabelSelector)
+			if err != nil {
+				return nil, err
+			}
+			pods, err := k.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: p.LabelSelector})
+			if err != nil {
+				return nil, err
+			}
+			specs := map[string]*corev1.Pod{}
+			for i := range pods.Items {
+				specs[pods.Items[i].Namespace+"/"+pods.Items[i].Name] = &pods.Items[i]
+			}
+			type podRow struct {
+				Namespace  string           `json:"namespace"`
+				Name       string           `json:"name"`
+				CPU        string           `json:"cpu"`
+				Memory     string           `json:"memory"`
+				Containers []containerUsage `json:"containers"`
+				Window     string           `json:"window,omitempty"`
+				cpu, mem   resource.Quantity
+			}
+			rows := []podRow{}
+			for _, item := range list.Items {
+				row := podRow{Namespace: item.GetNamespace(), Name: item.GetName()}
+				row.Window, _, _ = unstructured.NestedString(item.Object, "window")
+				spec := specs[row.Namespace+"/"+row.Name]
+				containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+				for _, c := range containers {
+					cm, _ := c.(map[string]any)
+					name, _, _ := unstructured.NestedString(cm, "name")
+					usage, _, _ := unstructured.NestedStringMap(cm, "usage")
+					cu := newContainerUsage(name, usage, spec)
+					row.cpu.Add(cu.cpu)
+					row.mem.Add(cu.mem)
+					row.Containers = append(row.Containers, cu)
+				}
+				sort.Slice(row.Containers, func(i, j int) bool { return row.Containers[i].Name < row.Containers[j].Name })
+				row.CPU, row.Memory = row.cpu.String(), row.mem.String()
+				rows = append(rows, row)
+			}
+			sort.SliceStable(rows, func(i, j int) bool {
+				if sortBy == corev1.ResourceCPU {
+					return rows[i].cpu.Cmp(rows[j].cpu) > 0
+				}
+				return rows[i].mem.Cmp(rows[j].mem) > 0
+			})
+			total := len(rows)
+			if len(rows) > p.Limit {
+				rows = rows[:p.Limit]
+			}
+			return map[string]any{"pods": rows, "total": total, "sortBy": string(sortBy)}, nil
+		}),
+	})
+
+	// top-nodes
+	reg.Register(mcp.Tool{
+		Name:         "top-nodes",
+		Description:  "Show node CPU/memory usage from metrics-server against allocatable",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("top-nodes", 10, 5)
+			k := k.Current()
+			var p struct {
+				LabelSelector string
+				SortBy        string
+				Limit         int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			sortBy, err := metricsSortKey(p.SortBy)
+			if err != nil {
+				return nil, err
+			}
+			if p.Limit <= 0 {
+				p.Limit = 20
+			}
+			list, err := listMetrics(ctx, k, nodeMetricsGVR, "", p.LabelSelector)
+			if err != nil {
+				return nil, err
+			}
+			nodes, err := k.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: p.LabelSelector})
+			if err != nil {
+				return nil, err
+			}
+			type nodeRow struct {
+				Name          string `json:"name"`
+				CPU           string `json:"cpu"`
+				CPUPercent    int64  `json:"cpuPercent"`
+				Memory        string `json:"memory"`
+				MemoryPercent int64  `json:"memoryPercent"`
+				usage         resource.Quantity
+				percent       int64
+			}
+			rows := []nodeRow{}
+			reported := map[string]bool{}
+			allocatable := map[string]corev1.ResourceList{}
+			for _, n := range nodes.Items {
+				allocatable[n.Name] = n.Status.Allocatable
+			}
+			for _, item := range list.Items {
+				usage, _, _ := unstructured.NestedStringMap(item.Object, "usage")
+				cpu, mem := parseQuantity(usage["cpu"]), parseQuantity(usage["memory"])
+				alloc := allocatable[item.GetName()]
+				row := nodeRow{Name: item.GetName(), CPU: cpu.String(), CPUPercent: percentOf(cpu, alloc[corev1.ResourceCPU]), Memory: mem.String(), MemoryPercent: percentOf(mem, alloc[corev1.ResourceMemory])}
+				row.usage, row.percent = mem, row.MemoryPercent
+				if sortBy == corev1.ResourceCPU {
+					row.usage, row.percent = cpu, row.CPUPercent
+				}
+				reported[row.Name] = true
+				rows = append(rows, row)
+			}
+			sort.SliceStable(rows, func(i, j int) bool {
+				if rows[i].percent != rows[j].percent {
+					return rows[i].percent > rows[j].percent
+				}
+				return rows[i].usage.Cmp(rows[j].usage) > 0
+			})
+			// nodes without metrics are usually NotReady or have a broken kubelet
+			var missing []string
+			for _, n := range nodes.Items {
+				if !reported[n.Name] {
+					missing = append(missing, n.Name)
+				}
+			}
+			if len(rows) > p.Limit {
+				rows = rows[:p.Limit]
+			}
+			out := map[string]any{"nodes": rows, "sortBy": string(sortBy)}
+			if len(missing) > 0 {
+				out["missingMetrics"] = missing
+			}
+			return out, nil
+		}),
+	})
+}
+
+func metricsSortKey(s string) (corev1.ResourceName, error) {
+	switch s {
+	case "", "memory":
+		return corev1.ResourceMemory, nil
+	case "cpu":
+		return corev1.ResourceCPU, nil
+	}
+	return "", fmt.Errorf("invalid sortBy %q (use cpu or memory)", s)
+}
+
+// listMetrics lists resource metrics, first checking discovery so a missing
+// metrics-server reads as such instead of a bare 404.
+func listMetrics(ctx context.Context, k *k8s.Clients, gvr schema.GroupVersionResource, ns, selector string) (*unstructured.UnstructuredList, error) {
+	if _, err := k.Discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errNoMetrics
+		}
+		return nil, err
+	}
+	list, err := k.Dynamic.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, errNoMetrics
+	case apierrors.IsServiceUnavailable(err):
+		return nil, fmt.Errorf("metrics-server is installed but not answering (APIService v1beta1.metrics.k8s.io unavailable): %w", err)
+	}
+	return list, err
+}
+
+func newContainerUsage(name string, usage map[string]string, pod *corev1.Pod) containerUsage {
+	cu := containerUsage{Name: name, cpu: parseQuantity(usage["cpu"]), mem: parseQuantity(usage["memory"])}
+	cu.CPU, cu.Memory = cu.cpu.String(), cu.mem.String()
+	if pod == nil {
+		return cu
+	}
+	for _, c := range append(append([]corev1.Container{}, pod.Spec.Containers...), pod.Spec.InitContainers...) {
+		if c.Name != name {
+			continue
+		}
+		reqs, limits := c.Resources.Requests, c.Resources.Limits
+		if q, ok := reqs[corev1.ResourceCPU]; ok {
+			cu.CPURequest, cu.CPUPercentOfReq = q.String(), percentOf(cu.cpu, q)
+		}
+		if q, ok := limits[corev1.ResourceCPU]; ok {
+			cu.CPULimit, cu.CPUPercentOfLim = q.String(), percentOf(cu.cpu, q)
+		}
+		if q, ok := reqs[corev1.ResourceMemory]; ok {
+			cu.MemoryRequest, cu.MemPercentOfReq = q.String(), percentOf(cu.mem, q)
+		}
+		if q, ok := limits[corev1.ResourceMemory]; ok {
+			cu.MemoryLimit, cu.MemPercentOfLim = q.String(), percentOf(cu.mem, q)
+		}
+		break
+	}
+	return cu
+}
+
+func parseQuantity(s string) resource.Quantity {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	return q
+}
@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// protectedNamespace reports whether ns-delete refuses the namespace:
+// default and the kube-* system namespaces (kube-system, kube-flannel, ...).
+func protectedNamespace(name string) bool {
+	return name == metav1.NamespaceDefault || strings.HasPrefix(name, "kube-")
+}
+
+func RegisterNamespaces(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "ns-describe", Description: "Describe a namespace: labels, Pod Security levels, quota usage, limit ranges and workload counts", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "ns-create", Description: "Create a namespace with optional labels and annotations", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "ns-delete", Description: "Delete a namespace after reporting the resources and finalizers it still holds", DirectResult: true, Handler: notReady})
+		return
+	}
+	// ns-describe
+	reg.Register(mcp.Tool{
+		Name:         "ns-describe",
+		Description:  "Describe a namespace: labels, Pod Security levels, quota usage, limit ranges and workload counts",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("ns-describe", 10, 5)
+			k := k.Current()
+			var p struct{ Name string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Name == "" {
+				p.Name = k.DefaultNamespace
+			}
+			ns, err := k.Clientset.CoreV1().Namespaces().Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{
+				"name":              ns.Name,
+				"status":            ns.Status.Phase,
+				"creationTimestamp": ns.CreationTimestamp,
+				"labels":            ns.Labels,
+				"annotations":       ns.Annotations,
+				"podSecurity":       podSecurityLevels(ns.Labels),
+			}
+			if len(ns.Status.Conditions) > 0 {
+				out["conditions"] = ns.Status.Conditions
+			}
+			if fin := namespaceFinalizers(ns); len(fin) > 0 {
+				out["finalizers"] = fin
+			}
+			quotas, err := k.Clientset.CoreV1().ResourceQuotas(ns.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			out["resourceQuotas"] = quotaUsage(quotas.Items)
+			limits, err := k.Clientset.CoreV1().LimitRanges(ns.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			type limitRow struct {
+				Name   string                  `json:"name"`
+				Limits []corev1.LimitRangeItem `json:"limits"`
+			}
+			lr := []limitRow{}
+			for _, l := range limits.Items {
+				lr = append(lr, limitRow{Name: l.Name, Limits: l.Spec.Limits})
+			}
+			out["limitRanges"] = lr
+			inv := namespaceInventory(ctx, k.Clientset, ns.Name)
+			out["counts"] = inv.counts
+			if len(inv.errors) > 0 {
+				out["countErrors"] = inv.errors
+			}
+			return out, nil
+		}),
+	})
+
+	// ns-create
+	reg.Register(mcp.Tool{
+		Name:         "ns-create",
+		Description:  "Create a namespace with optional labels and annotations",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("ns-create", 5, 2)
+			k := k.Current()
+			var p struct {
+				Name        string
+				Labels      map[string]string
+				Annotations map[string]string
+				DryRun      *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Name == "" {
+				return nil, errors.New("name is required")
+			}
+			if err := authz.EnforceMutating("ns-create", p.Name, "Namespace"); err != nil {
+				return nil, err
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "create", Resource: "namespaces", Name: p.Name}); err != nil {
+				return nil, err
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			dr := []string{}
+			if dryRun {
+				dr = []string{"All"}
+			}
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Labels: p.Labels, Annotations: p.Annotations}}
+			created, err := k.Clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{DryRun: dr})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"name": created.Name, "labels": created.Labels, "podSecurity": podSecurityLevels(created.Labels), "dryRun": dryRun}, nil
+		},
+	})
+
+	// ns-delete
+	reg.Register(mcp.Tool{
+		Name:         "ns-delete",
+		Description:  "Delete a namespace after reporting the resources and finalizers it still holds",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("ns-delete", 5, 2)
+			k := k.Current()
+			var p struct {
+				Name   string
+				Force  bool // delete even if the namespace still holds resources
+				DryRun *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Name == "" {
+				return nil, errors.New("name is required")
+			}
+			if protectedNamespace(p.Name) {
+				return nil, &authz.GuardError{Code: "NS_PROTECTED", Message: "namespace " + p.Name + " is a system namespace and cannot be deleted"}
+			}
+			if err := authz.EnforceMutating("ns-delete", p.Name, "Namespace"); err != nil {
+				return nil, err
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "delete", Resource: "namespaces", Name: p.Name}); err != nil {
+				return nil, err
+			}
+			ns, err := k.Clientset.CoreV1().Namespaces().Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			inv := namespaceInventory(ctx, k.Clientset, ns.Name)
+			var warnings []string
+			var contents []string
+			for _, kind := range sortedKeys(inv.counts) {
+				if n := inv.counts[kind]; n > 0 {
+					contents = append(contents, fmt.Sprintf("%d %s", n, kind))
+				}
+			}
+			if len(contents) > 0 {
+				warnings = append(warnings, "namespace still contains "+strings.Join(contents, ", ")+"; all of it will be deleted")
+			}
+			if len(inv.finalizing) > 0 {
+				warnings = append(warnings, "objects with finalizers may hold the namespace in Terminating: "+strings.Join(inv.finalizing, ", "))
+			}
+			if len(ns.Finalizers) > 0 {
+				warnings = append(warnings, "namespace has metadata finalizers "+strings.Join(ns.Finalizers, ", ")+" that a controller must remove")
+			}
+			if ns.Status.Phase == corev1.NamespaceTerminating {
+				warnings = append(warnings, "namespace is already Terminating")
+			}
+			for _, e := range inv.errors {
+				warnings = append(warnings, "could not inspect "+e)
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			out := map[string]any{"name": ns.Name, "counts": inv.counts, "warnings": warnings, "dryRun": dryRun}
+			// objects Kubernetes creates in every namespace do not block
+			var blocking []string
+			for _, kind := range sortedKeys(inv.counts) {
+				if n := inv.counts[kind] - inv.system[kind]; n > 0 {
+					blocking = append(blocking, fmt.Sprintf("%d %s", n, kind))
+				}
+			}
+			if len(blocking) > 0 && !p.Force && !dryRun {
+				return nil, fmt.Errorf("namespace %s still contains %s; pass force=true to delete it with its contents", ns.Name, strings.Join(blocking, ", "))
+			}
+			// what could not be listed may not be empty
+			if len(inv.errors) > 0 && !p.Force && !dryRun {
+				return nil, fmt.Errorf("could not check what namespace %s still contains (%s); pass force=true to delete it anyway", ns.Name, strings.Join(inv.errors, "; "))
+			}
+			dr := []string{}
+			if dryRun {
+				dr = []string{"All"}
+			}
+			if err := k.Clientset.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{DryRun: dr}); err != nil {
+				return nil, err
+			}
+			out["deleted"] = !dryRun
+			return out, nil
+		},
+	})
+}
+
+// podSecurityLevels extracts the Pod Security Admission enforce/audit/warn
+// levels (and pinned versions) from namespace labels.
+func podSecurityLevels(labels map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		if v, ok := labels["pod-security.kubernetes.io/"+mode]; ok {
+			out[mode] = v
+		}
+		if v, ok := labels["pod-security.kubernetes.io/"+mode+"-version"]; ok {
+			out[mode+"Version"] = v
+		}
+	}
+	return out
+}
+
+func namespaceFinalizers(ns *corev1.Namespace) []string {
+	var out []string
+	for _, f := range ns.Spec.Finalizers {
+		out = append(out, string(f))
+	}
+	return append(out, ns.Finalizers...)
+}
+
+// quotaUsage renders each ResourceQuota as used vs hard per resource.
+func quotaUsage(quotas []corev1.ResourceQuota) []map[string]any {
+	out := []map[string]any{}
+	for _, q := range quotas {
+		type usage struct {
+			Hard    string `json:"hard"`
+			Used    string `json:"used"`
+			Percent int64  `json:"percent"`
+		}
+		res := map[string]usage{}
+		for name, hard := range q.Status.Hard {
+			used := q.Status.Used[name]
+			res[string(name)] = usage{Hard: hard.String(), Used: used.String(), Percent: percentOf(used, hard)}
+		}
+		// status is filled in by the quota controller; fall back to spec
+		if len(res) == 0 {
+			for name, hard := range q.Spec.Hard {
+				res[string(name)] = usage{Hard: hard.String(), Used: "unknown"}
+			}
+		}
+		out = append(out, map[string]any{"name": q.Name, "resources": res})
+	}
+	return out
+}
+
+// nsInventory counts namespaced objects by kind and notes objects carrying
+// finalizers. system counts, per kind, the objects included in counts that
+// Kubernetes manages itself (see systemManaged).
+type nsInventory struct {
+	counts     map[string]int
+	system     map[string]int
+	finalizing []string
+	errors     []string
+}
+
+// systemManaged reports whether obj is created by Kubernetes in every
+// namespace: the kube-root-ca.crt ConfigMap and service account token
+// Secrets.
+func systemManaged(obj runtime.Object) bool {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		return o.Name == "kube-root-ca.crt"
+	case *corev1.Secret:
+		return o.Type == corev1.SecretTypeServiceAccountToken
+	}
+	return false
+}
+
+func namespaceInventory(ctx context.Context, cs kubernetes.Interface, ns string) nsInventory {
+	opts := metav1.ListOptions{}
+	listers := []struct {
+		kind string
+		list func() (runtime.Object, error)
+	}{
+		{"Pod", func() (runtime.Object, error) { return cs.CoreV1().Pods(ns).List(ctx, opts) }},
+		{"Deployment", func() (runtime.Object, error) { return cs.AppsV1().Deployments(ns).List(ctx, opts) }},
+		{"StatefulSet", func() (runtime.Object, error) { return cs.AppsV1().StatefulSets(ns).List(ctx, opts) }},
+		{"DaemonSet", func() (runtime.Object, error) { return cs.AppsV1().DaemonSets(ns).List(ctx, opts) }},
+		{"ReplicaSet", func() (runtime.Object, error) { return cs.AppsV1().ReplicaSets(ns).List(ctx, opts) }},
+		{"Job", func() (runtime.Object, error) { return cs.BatchV1().Jobs(ns).List(ctx, opts) }},
+		{"CronJob", func() (runtime.Object, error) { return cs.BatchV1().CronJobs(ns).List(ctx, opts) }},
+		{"Service", func() (runtime.Object, error) { return cs.CoreV1().Services(ns).List(ctx, opts) }},
+		{"Ingress", func() (runtime.Object, error) { return cs.NetworkingV1().Ingresses(ns).List(ctx, opts) }},
+		{"ConfigMap", func() (runtime.Object, error) { return cs.CoreV1().ConfigMaps(ns).List(ctx, opts) }},
+		{"Secret", func() (runtime.Object, error) { return cs.CoreV1().Secrets(ns).List(ctx, opts) }},
+		{"PersistentVolumeClaim", func() (runtime.Object, error) { return cs.CoreV1().PersistentVolumeClaims(ns).List(ctx, opts) }},
+	}
+	inv := nsInventory{counts: map[string]int{}, system: map[string]int{}}
+	for _, l := range listers {
+		list, err := l.list()
+		if err != nil {
+			inv.errors = append(inv.errors, l.kind+": "+err.Error())
+			continue
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			continue
+		}
+		inv.counts[l.kind] = len(items)
+		for _, item := range items {
+			if systemManaged(item) {
+				inv.system[l.kind]++
+			}
+			if obj, err := meta.Accessor(item); err == nil && len(obj.GetFinalizers()) > 0 {
+				inv.finalizing = append(inv.finalizing, l.kind+"/"+obj.GetName()+" ("+strings.Join(obj.GetFinalizers(), ",")+")")
+			}
+		}
+	}
+	return inv
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
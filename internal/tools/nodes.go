@@ -0,0 +1,566 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+func RegisterNodes(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "nodes-list", Description: "List nodes with readiness, roles, version and pressure conditions", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "nodes-get", Description: "Describe a node: conditions, taints, capacity vs allocatable and pod requests/limits", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "nodes-cordon", Description: "Mark a node unschedulable", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "nodes-uncordon", Description: "Mark a node schedulable again", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "nodes-drain", Description: "Cordon a node and evict its pods, respecting PodDisruptionBudgets (dry-run lists the pods)", DirectResult: true, Handler: notReady})
+		return
+	}
+	// nodes-list
+	reg.Register(mcp.Tool{
+		Name:         "nodes-list",
+		Description:  "List nodes with readiness, roles, version and pressure conditions",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("nodes-list", 10, 5)
+			k := k.Current()
+			var p struct {
+				LabelSelector string `json:"labelSelector"`
+				Limit         *int   `json:"limit"`
+			}
+			_ = json.Unmarshal(params, &p)
+			list, err := k.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: p.LabelSelector})
+			if err != nil {
+				return nil, err
+			}
+			type row struct {
+				Name, Status, Version, InternalIP string
+				Roles                             []string
+				Pressure                          []string `json:",omitempty"`
+				Taints                            int
+				Age                               any
+			}
+			var rows []row
+			for _, n := range list.Items {
+				rows = append(rows, row{
+					Name:       n.Name,
+					Status:     nodeStatus(&n),
+					Version:    n.Status.NodeInfo.KubeletVersion,
+					InternalIP: nodeAddress(&n, corev1.NodeInternalIP),
+					Roles:      nodeRoles(&n),
+					Pressure:   nodePressure(&n),
+					Taints:     len(n.Spec.Taints),
+					Age:        n.CreationTimestamp,
+				})
+			}
+			if p.Limit != nil && *p.Limit > 0 && len(rows) > *p.Limit {
+				rows = rows[:*p.Limit]
+			}
+			return map[string]any{"nodes": rows}, nil
+		}),
+	})
+
+	// nodes-get
+	reg.Register(mcp.Tool{
+		Name:         "nodes-get",
+		Description:  "Describe a node: conditions, taints, capacity vs allocatable and pod requests/limits",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("nodes-get", 10, 5)
+			k := k.Current()
+			var p struct{ Name string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			node, err := k.Clientset.CoreV1().Nodes().Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			pods, err := podsOnNode(ctx, k, node.Name)
+			if err != nil {
+				return nil, err
+			}
+			type podRow struct {
+				Namespace, Name              string
+				CPURequests, CPULimits       string
+				MemoryRequests, MemoryLimits string
+			}
+			var podRows []podRow
+			totalReqs, totalLimits := corev1.ResourceList{}, corev1.ResourceList{}
+			for i := range pods {
+				reqs, limits := podRequestsAndLimits(&pods[i])
+				addResources(totalReqs, reqs)
+				addResources(totalLimits, limits)
+				podRows = append(podRows, podRow{
+					Namespace:      pods[i].Namespace,
+					Name:           pods[i].Name,
+					CPURequests:    quantityString(reqs, corev1.ResourceCPU),
+					CPULimits:      quantityString(limits, corev1.ResourceCPU),
+					MemoryRequests: quantityString(reqs, corev1.ResourceMemory),
+					MemoryLimits:   quantityString(limits, corev1.ResourceMemory),
+				})
+			}
+			allocated := map[string]any{}
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+				alloc := node.Status.Allocatable[name]
+				allocated[string(name)] = map[string]any{
+					"requests":        quantityString(totalReqs, name),
+					"requestsPercent": percentOf(totalReqs[name], alloc),
+					"limits":          quantityString(totalLimits, name),
+					"limitsPercent":   percentOf(totalLimits[name], alloc),
+					"allocatable":     alloc.String(),
+				}
+			}
+			allocated["pods"] = map[string]any{"count": len(pods), "allocatable": node.Status.Allocatable.Pods().String()}
+			var conditions []map[string]any
+			for _, c := range node.Status.Conditions {
+				conditions = append(conditions, map[string]any{"type": c.Type, "status": c.Status, "reason": c.Reason, "message": c.Message, "lastTransitionTime": c.LastTransitionTime})
+			}
+			info := node.Status.NodeInfo
+			out := map[string]any{
+				"metadata":      map[string]any{"name": node.Name, "labels": node.Labels, "creationTimestamp": node.CreationTimestamp},
+				"status":        nodeStatus(node),
+				"roles":         nodeRoles(node),
+				"unschedulable": node.Spec.Unschedulable,
+				"taints":        node.Spec.Taints,
+				"conditions":    conditions,
+				"pressure":      nodePressure(node),
+				"addresses":     node.Status.Addresses,
+				"nodeInfo": map[string]any{
+					"kubeletVersion":          info.KubeletVersion,
+					"osImage":                 info.OSImage,
+					"kernelVersion":           info.KernelVersion,
+					"containerRuntimeVersion": info.ContainerRuntimeVersion,
+					"architecture":            info.Architecture,
+				},
+				"capacity":    node.Status.Capacity,
+				"allocatable": node.Status.Allocatable,
+				"allocated":   allocated,
+				"pods":        podRows,
+			}
+			return out, nil
+		}),
+	})
+
+	// nodes-cordon
+	reg.Register(mcp.Tool{
+		Name:         "nodes-cordon",
+		Description:  "Mark a node unschedulable",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("nodes-cordon", 5, 2)
+			k := k.Current()
+			var p struct {
+				Name   string
+				DryRun *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return setUnschedulable(ctx, k, "nodes-cordon", p.Name, true, p.DryRun == nil || *p.DryRun)
+		},
+	})
+
+	// nodes-uncordon
+	reg.Register(mcp.Tool{
+		Name:         "nodes-uncordon",
+		Description:  "Mark a node schedulable again",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("nodes-uncordon", 5, 2)
+			k := k.Current()
+			var p struct {
+				Name   string
+				DryRun *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return setUnschedulable(ctx, k, "nodes-uncordon", p.Name, false, p.DryRun == nil || *p.DryRun)
+		},
+	})
+
+	// nodes-drain
+	reg.Register(mcp.Tool{
+		Name:         "nodes-drain",
+		Description:  "Cordon a node and evict its pods, respecting PodDisruptionBudgets (dry-run lists the pods)",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("nodes-drain", 2, 1)
+			k := k.Current()
+			var p struct {
+				Name               string
+				DeleteEmptyDirData bool
+				Force              bool
+				GracePeriodSeconds *int64
+				TimeoutSeconds     *int
+				DryRun             *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			if err := authz.EnforceMutating("nodes-drain", "", "Node"); err != nil {
+				return nil, err
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "patch", Resource: "nodes", Name: p.Name}); err != nil {
+				return nil, err
+			}
+			node, err := k.Clientset.CoreV1().Nodes().Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			pods, err := podsOnNode(ctx, k, node.Name)
+			if err != nil {
+				return nil, err
+			}
+			var results []drainPod
+			var toEvict []corev1.Pod
+			var blocked []string
+			for _, pod := range pods {
+				skip, block := classifyDrainPod(&pod, p.DeleteEmptyDirData, p.Force)
+				switch {
+				case skip != "":
+					results = append(results, drainPod{Namespace: pod.Namespace, Name: pod.Name, Status: "skipped", Reason: skip})
+				case block != "":
+					results = append(results, drainPod{Namespace: pod.Namespace, Name: pod.Name, Status: "blocked", Reason: block})
+					blocked = append(blocked, pod.Namespace+"/"+pod.Name+": "+block)
+				default:
+					toEvict = append(toEvict, pod)
+				}
+			}
+			if len(blocked) > 0 {
+				return nil, fmt.Errorf("cannot drain node %s; nothing was changed:\n%s", node.Name, strings.Join(blocked, "\n"))
+			}
+			if dryRun {
+				pdbs := newPDBLookup(k)
+				for _, pod := range toEvict {
+					results = append(results, drainPod{Namespace: pod.Namespace, Name: pod.Name, Status: "would-evict", Reason: pdbs.blocking(ctx, &pod)})
+				}
+				return map[string]any{"node": node.Name, "dryRun": true, "wouldCordon": !node.Spec.Unschedulable, "pods": results}, nil
+			}
+			if !node.Spec.Unschedulable {
+				if _, err := setUnschedulable(ctx, k, "nodes-drain", node.Name, true, false); err != nil {
+					return nil, err
+				}
+			}
+			timeout := 5 * time.Minute
+			if p.TimeoutSeconds != nil && *p.TimeoutSeconds > 0 {
+				timeout = time.Duration(*p.TimeoutSeconds) * time.Second
+			}
+			evictCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			// evict concurrently like kubectl, so one PDB-blocked pod does not stall the rest
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			done := 0
+			evicted := make([]drainPod, len(toEvict))
+			for i := range toEvict {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					pod := &toEvict[i]
+					attempts, err := evictPod(evictCtx, k, pod, p.GracePeriodSeconds)
+					r := drainPod{Namespace: pod.Namespace, Name: pod.Name, Status: "evicted", Attempts: attempts}
+					if err != nil {
+						r.Status, r.Reason = "failed", err.Error()
+					}
+					evicted[i] = r
+					mu.Lock()
+					done++
+					mcp.ReportProgress(ctx, float64(done), float64(len(toEvict)), r.Status+" "+pod.Namespace+"/"+pod.Name)
+					mu.Unlock()
+				}(i)
+			}
+			wg.Wait()
+			results = append(results, evicted...)
+			failed := 0
+			for _, r := range evicted {
+				if r.Status == "failed" {
+					failed++
+				}
+			}
+			return map[string]any{"node": node.Name, "cordoned": true, "evicted": len(evicted) - failed, "failed": failed, "pods": results}, nil
+		},
+	})
+}
+
+// setUnschedulable cordons or uncordons a node through the authz guards.
+func setUnschedulable(ctx context.Context, k *k8s.Clients, tool, name string, unschedulable, dryRun bool) (any, error) {
+	if err := authz.EnforceMutating(tool, "", "Node"); err != nil {
+		return nil, err
+	}
+	if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "patch", Resource: "nodes", Name: name}); err != nil {
+		return nil, err
+	}
+	dr := []string{}
+	if dryRun {
+		dr = []string{"All"}
+	}
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	var node *corev1.Node
+	// setting a fixed value is idempotent
+	err := k8s.Retry(ctx, func() error {
+		var err error
+		node, err = k.Clientset.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{DryRun: dr})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"node": node.Name, "unschedulable": node.Spec.Unschedulable, "dryRun": dryRun}, nil
+}
+
+// drainPod is the per-pod outcome of nodes-drain.
+type drainPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is skipped, blocked, would-evict, evicted or failed
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+// classifyDrainPod mirrors kubectl drain's filters: mirror and DaemonSet pods
+// are skipped; emptyDir and unmanaged pods block the drain unless allowed.
+func classifyDrainPod(pod *corev1.Pod, deleteEmptyDirData, force bool) (skip, block string) {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return "mirror pod", ""
+	}
+	ctrl := metav1.GetControllerOf(pod)
+	if ctrl != nil && ctrl.Kind == "DaemonSet" {
+		return "managed by DaemonSet " + ctrl.Name, ""
+	}
+	if err := authz.EnforceMutating("nodes-drain", pod.Namespace, "Pod"); err != nil {
+		return "", err.Error()
+	}
+	if ctrl == nil && !force {
+		return "", "not managed by a controller (set force to evict it anyway)"
+	}
+	if !deleteEmptyDirData {
+		for _, v := range pod.Spec.Volumes {
+			if v.EmptyDir != nil {
+				return "", "uses emptyDir volume " + v.Name + " (set deleteEmptyDirData to evict it and lose the data)"
+			}
+		}
+	}
+	return "", ""
+}
+
+// evictPod evicts pod through the Eviction API, retrying while a
+// PodDisruptionBudget refuses (429), then waits for the pod to be deleted.
+func evictPod(ctx context.Context, k *k8s.Clients, pod *corev1.Pod, gracePeriod *int64) (int, error) {
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod},
+	}
+	attempts := 0
+	for {
+		attempts++
+		err := k.Clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		if err == nil || apierrors.IsNotFound(err) {
+			break
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			return attempts, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, fmt.Errorf("timed out while a PodDisruptionBudget blocked eviction: %v", err)
+		case <-time.After(5 * time.Second):
+		}
+	}
+	for {
+		cur, err := k.Clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && cur.UID != pod.UID) {
+			return attempts, nil
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, errors.New("evicted but timed out waiting for the pod to terminate")
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// pdbLookup caches PodDisruptionBudgets per namespace for dry-run previews.
+type pdbLookup struct {
+	k    *k8s.Clients
+	byNS map[string][]policyv1.PodDisruptionBudget
+}
+
+func newPDBLookup(k *k8s.Clients) *pdbLookup {
+	return &pdbLookup{k: k, byNS: map[string][]policyv1.PodDisruptionBudget{}}
+}
+
+// blocking describes a PDB that currently allows no disruption of pod.
+func (l *pdbLookup) blocking(ctx context.Context, pod *corev1.Pod) string {
+	pdbs, ok := l.byNS[pod.Namespace]
+	if !ok {
+		if list, err := l.k.Clientset.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{}); err == nil {
+			pdbs = list.Items
+		}
+		l.byNS[pod.Namespace] = pdbs
+	}
+	for _, pdb := range pdbs {
+		sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || sel.Empty() || !sel.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed < 1 {
+			return "PodDisruptionBudget " + pdb.Name + " currently allows 0 disruptions; eviction will wait for it"
+		}
+	}
+	return ""
+}
+
+// podsOnNode returns the non-terminated pods scheduled on node, like
+// `kubectl describe node`.
+func podsOnNode(ctx context.Context, k *k8s.Clients, node string) ([]corev1.Pod, error) {
+	list, err := k.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + node + ",status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		return nil, err
+	}
+	// filter again: not every backend honors field selectors
+	var out []corev1.Pod
+	for _, p := range list.Items {
+		if p.Spec.NodeName == node && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// nodeStatus renders readiness the way kubectl does, e.g. "Ready,SchedulingDisabled".
+func nodeStatus(n *corev1.Node) string {
+	status := "Unknown"
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			switch c.Status {
+			case corev1.ConditionTrue:
+				status = "Ready"
+			case corev1.ConditionFalse:
+				status = "NotReady"
+			}
+		}
+	}
+	if n.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodePressure lists the pressure/unavailable conditions that are currently true.
+func nodePressure(n *corev1.Node) []string {
+	var out []string
+	for _, c := range n.Status.Conditions {
+		if c.Type != corev1.NodeReady && c.Status == corev1.ConditionTrue {
+			out = append(out, string(c.Type))
+		}
+	}
+	return out
+}
+
+func nodeRoles(n *corev1.Node) []string {
+	var roles []string
+	for l := range n.Labels {
+		if r, ok := strings.CutPrefix(l, "node-role.kubernetes.io/"); ok && r != "" {
+			roles = append(roles, r)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func nodeAddress(n *corev1.Node, typ corev1.NodeAddressType) string {
+	for _, a := range n.Status.Addresses {
+		if a.Type == typ {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+// podRequestsAndLimits computes a pod's effective requests and limits as the
+// scheduler does: the sum of app and sidecar containers, at least the largest
+// regular init container, plus pod overhead.
+func podRequestsAndLimits(pod *corev1.Pod) (reqs, limits corev1.ResourceList) {
+	reqs, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResources(reqs, c.Resources.Requests)
+		addResources(limits, c.Resources.Limits)
+	}
+	sidecarReqs, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(reqs, c.Resources.Requests)
+			addResources(limits, c.Resources.Limits)
+			addResources(sidecarReqs, c.Resources.Requests)
+			addResources(sidecarLimits, c.Resources.Limits)
+			continue
+		}
+		// a regular init container runs alongside the sidecars started before it
+		initReqs, initLimits := sidecarReqs.DeepCopy(), sidecarLimits.DeepCopy()
+		addResources(initReqs, c.Resources.Requests)
+		addResources(initLimits, c.Resources.Limits)
+		maxResources(reqs, initReqs)
+		maxResources(limits, initLimits)
+	}
+	addResources(reqs, pod.Spec.Overhead)
+	if len(limits) > 0 {
+		addResources(limits, pod.Spec.Overhead)
+	}
+	return reqs, limits
+}
+
+func addResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		v := dst[name]
+		v.Add(q)
+		dst[name] = v
+	}
+}
+
+func maxResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if v, ok := dst[name]; !ok || q.Cmp(v) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+func quantityString(l corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := l[name]
+	if !ok {
+		return "0"
+	}
+	return q.String()
+}
+
+// percentOf returns used as a whole percentage of total (0 when total is zero).
+func percentOf(used, total resource.Quantity) int64 {
+	if total.IsZero() {
+		return 0
+	}
+	return used.MilliValue() * 100 / total.MilliValue()
+}
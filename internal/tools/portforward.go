@@ -0,0 +1,316 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+const (
+	maxForwardSessions = 16
+	forwardReadyWait   = 15 * time.Second
+)
+
+// forwarder is the part of portforward.PortForwarder a session uses.
+type forwarder interface {
+	ForwardPorts() error
+	GetPorts() ([]portforward.ForwardedPort, error)
+}
+
+// newForwarder builds a SPDY port-forwarder to a pod, listening on address.
+// Tests replace it to run without an API server.
+var newForwarder = func(k *k8s.Clients, namespace, pod, address string, port int, stop <-chan struct{}, ready chan struct{}) (forwarder, error) {
+	if k.RestConfig == nil {
+		return nil, errors.New("port-forward requires a live API server")
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(k.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	u := k.Clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, u)
+	// port 0 on the local side picks a free port
+	return portforward.NewOnAddresses(dialer, []string{address}, []string{"0:" + strconv.Itoa(port)}, stop, ready, io.Discard, io.Discard)
+}
+
+// forwardSession is one running port-forward.
+type forwardSession struct {
+	ID           string    `json:"id"`
+	Namespace    string    `json:"namespace"`
+	Pod          string    `json:"pod"`
+	Service      string    `json:"service,omitempty"`
+	RemotePort   int       `json:"remotePort"`
+	LocalAddress string    `json:"localAddress"`
+	URL          string    `json:"url"`
+	StartedAt    time.Time `json:"startedAt"`
+	Status       string    `json:"status"` // active or failed
+	Error        string    `json:"error,omitempty"`
+	stop         chan struct{}
+}
+
+// forwardSessions holds the sessions of this server process. It outlives
+// client reloads: sessions end on portforward-stop or when the server exits.
+type forwardSessions struct {
+	mu     sync.Mutex
+	m      map[string]*forwardSession
+	nextID int
+}
+
+var forwards = &forwardSessions{m: map[string]*forwardSession{}}
+
+func (f *forwardSessions) list() []forwardSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]forwardSession, 0, len(f.m))
+	for _, s := range f.m {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+// remove stops a session and forgets it.
+func (f *forwardSessions) remove(id string) (*forwardSession, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.m[id]
+	if !ok {
+		return nil, false
+	}
+	delete(f.m, id)
+	if s.Status == "active" {
+		close(s.stop)
+	}
+	return s, true
+}
+
+// start runs a forwarder and waits until it listens. A forwarder that dies
+// later stays listed as failed until it is stopped.
+func (f *forwardSessions) start(k *k8s.Clients, s *forwardSession, address string) (forwardSession, error) {
+	f.mu.Lock()
+	if len(f.m) >= maxForwardSessions {
+		f.mu.Unlock()
+		return forwardSession{}, fmt.Errorf("too many port-forward sessions (max %d); stop one with portforward-stop", maxForwardSessions)
+	}
+	f.nextID++
+	s.ID = "pf-" + strconv.Itoa(f.nextID)
+	f.mu.Unlock()
+
+	s.stop = make(chan struct{})
+	ready := make(chan struct{})
+	fw, err := newForwarder(k, s.Namespace, s.Pod, address, s.RemotePort, s.stop, ready)
+	if err != nil {
+		return forwardSession{}, err
+	}
+	s.Status = "active"
+	done := make(chan error, 1)
+	go func() {
+		err := fw.ForwardPorts()
+		f.mu.Lock()
+		if s.Status == "active" {
+			s.Status = "failed"
+			s.Error = "forwarding stopped"
+			if err != nil {
+				s.Error = err.Error()
+			}
+		}
+		f.mu.Unlock()
+		done <- err
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		if err == nil {
+			err = errors.New("port-forward ended before it was ready")
+		}
+		return forwardSession{}, err
+	case <-time.After(forwardReadyWait):
+		close(s.stop)
+		return forwardSession{}, fmt.Errorf("port-forward to %s/%s was not ready within %s", s.Namespace, s.Pod, forwardReadyWait)
+	}
+	ports, err := fw.GetPorts()
+	if err == nil && len(ports) == 0 {
+		err = errors.New("no ports forwarded")
+	}
+	if err != nil {
+		close(s.stop)
+		return forwardSession{}, fmt.Errorf("port-forward started but reported no local port: %w", err)
+	}
+	s.LocalAddress = net.JoinHostPort(address, strconv.Itoa(int(ports[0].Local)))
+	s.URL = "http://" + s.LocalAddress
+	s.StartedAt = time.Now().UTC()
+	f.mu.Lock()
+	f.m[s.ID] = s
+	out := *s
+	f.mu.Unlock()
+	return out, nil
+}
+
+func RegisterPortForward(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "portforward-start", Description: "Forward a local port to a pod or service port; the session stays open until stopped", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "portforward-list", Description: "List active port-forward sessions and their local addresses", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "portforward-stop", Description: "Stop a port-forward session", DirectResult: true, Handler: notReady})
+		return
+	}
+	// portforward-start
+	reg.Register(mcp.Tool{
+		Name:         "portforward-start",
+		Description:  "Forward a local port to a pod or service port; the session stays open until stopped",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("portforward-start", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Pod, Service string
+				Port                    int    // pod port, or service port with service
+				Address                 string // local listen address (default 127.0.0.1)
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			if (p.Pod == "") == (p.Service == "") {
+				return nil, errors.New("exactly one of pod or service is required")
+			}
+			if p.Address == "" {
+				p.Address = "127.0.0.1"
+			}
+			ip := net.ParseIP(p.Address)
+			if ip == nil && p.Address != "localhost" {
+				return nil, fmt.Errorf("invalid address %q", p.Address)
+			}
+			// a forward on another interface exposes the pod to the network
+			if ip != nil && !ip.IsLoopback() && os.Getenv("MCP_K8S_PORTFORWARD_ALLOW_REMOTE") != "true" {
+				return nil, &authz.GuardError{Code: "PORTFORWARD_ADDRESS_NOT_ALLOWED", Message: "address " + p.Address + " is not a loopback address (set MCP_K8S_PORTFORWARD_ALLOW_REMOTE=true to listen on other interfaces)"}
+			}
+			if err := authz.EnforceMutating("portforward-start", p.Namespace, "Pod"); err != nil {
+				return nil, err
+			}
+			s := &forwardSession{Namespace: p.Namespace, Pod: p.Pod, Service: p.Service, RemotePort: p.Port}
+			if p.Service != "" {
+				pod, port, err := servicePod(ctx, k, p.Namespace, p.Service, p.Port)
+				if err != nil {
+					return nil, err
+				}
+				s.Pod, s.RemotePort = pod, port
+			}
+			if s.RemotePort <= 0 || s.RemotePort > 65535 {
+				return nil, errors.New("port is required (1-65535)")
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "portforward", Name: s.Pod, Namespace: p.Namespace}); err != nil {
+				return nil, err
+			}
+			return forwards.start(k, s, p.Address)
+		},
+	})
+
+	// portforward-list
+	reg.Register(mcp.Tool{
+		Name:         "portforward-list",
+		Description:  "List active port-forward sessions and their local addresses",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, _ json.RawMessage) (any, error) {
+			_ = authz.RateLimit("portforward-list", 10, 5)
+			return map[string]any{"sessions": forwards.list()}, nil
+		}),
+	})
+
+	// portforward-stop
+	reg.Register(mcp.Tool{
+		Name:         "portforward-stop",
+		Description:  "Stop a port-forward session",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("portforward-stop", 10, 5)
+			var p struct{ ID string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			s, ok := forwards.remove(p.ID)
+			if !ok {
+				return nil, fmt.Errorf("no port-forward session %q", p.ID)
+			}
+			return map[string]any{"id": s.ID, "stopped": true, "localAddress": s.LocalAddress}, nil
+		},
+	})
+}
+
+// servicePod picks a ready pod behind a service and resolves the service
+// port to the pod's container port, as kubectl port-forward svc/... does.
+// port may be 0 for a single-port service.
+func servicePod(ctx context.Context, k *k8s.Clients, namespace, name string, port int) (string, int, error) {
+	svc, err := k.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s has no selector", name)
+	}
+	var sp *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if int(svc.Spec.Ports[i].Port) == port || (port == 0 && len(svc.Spec.Ports) == 1) {
+			sp = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if sp == nil && port == 0 {
+		return "", 0, fmt.Errorf("service %s has several ports; port is required", name)
+	}
+	if sp == nil {
+		return "", 0, fmt.Errorf("service %s has no port %d", name, port)
+	}
+	pods, err := k.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String()})
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || !podReady(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		switch sp.TargetPort.Type {
+		case intstr.Int:
+			if sp.TargetPort.IntVal == 0 {
+				return pod.Name, int(sp.Port), nil
+			}
+			return pod.Name, int(sp.TargetPort.IntVal), nil
+		case intstr.String:
+			for _, c := range pod.Spec.Containers {
+				for _, cp := range c.Ports {
+					if cp.Name == sp.TargetPort.StrVal {
+						return pod.Name, int(cp.ContainerPort), nil
+					}
+				}
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("service %s has no ready pod serving port %d", name, sp.Port)
+}
@@ -9,7 +9,10 @@ import (
 	"github.com/example/mcp-k8s-server-go/internal/authz"
 	"github.com/example/mcp-k8s-server-go/pkg/k8s"
 	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -29,8 +32,9 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 		Name:         "resources-get",
 		Description:  "Get or list arbitrary resources by GVK",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("resources-get", 10, 5)
+			k := k.Current()
 			var p struct {
 				Group         *string
 				Version       string
@@ -80,7 +84,7 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 				summary = summary[:*p.Limit]
 			}
 			return map[string]any{"items": summary}, nil
-		},
+		}),
 	})
 
 	// resources-apply (server-side apply)
@@ -90,6 +94,7 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 		DirectResult: true,
 		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("resources-apply", 10, 5)
+			k := k.Current()
 			var p struct {
 				ManifestYAML string  `json:"manifestYAML"`
 				FieldManager *string `json:"fieldManager"`
@@ -120,6 +125,10 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 					continue
 				}
 				ns := obj.GetNamespace()
+				if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "patch", Group: gvr.Group, Resource: gvr.Resource, Name: obj.GetName(), Namespace: ns}); err != nil {
+					results = append(results, map[string]any{"error": err.Error()})
+					continue
+				}
 				ri := k.Dynamic.Resource(gvr).Namespace(ns)
 				fm := "mcp-k8s-server"
 				if p.FieldManager != nil && *p.FieldManager != "" {
@@ -129,7 +138,13 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 				if p.DryRun == nil || *p.DryRun {
 					dr = []string{"All"}
 				}
-				applied, err := ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, []byte(d), metav1.PatchOptions{FieldManager: fm, Force: ptrBool(true), DryRun: dr})
+				// server-side apply is idempotent, so transient failures are retried
+				var applied *unstructured.Unstructured
+				err = k8s.Retry(ctx, func() error {
+					var err error
+					applied, err = ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, []byte(d), metav1.PatchOptions{FieldManager: fm, Force: ptrBool(true), DryRun: dr})
+					return err
+				})
 				if err != nil {
 					results = append(results, map[string]any{"error": err.Error()})
 					continue
@@ -147,6 +162,7 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 		DirectResult: true,
 		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("resources-delete", 10, 5)
+			k := k.Current()
 			var p struct {
 				Group               *string
 				Version, Kind, Name string
@@ -168,6 +184,9 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 			if err := authz.EnforceMutating("resources-delete", ns, p.Kind); err != nil {
 				return nil, err
 			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "delete", Group: gvr.Group, Resource: gvr.Resource, Name: p.Name, Namespace: ns}); err != nil {
+				return nil, err
+			}
 			dr := []string{}
 			if p.DryRun == nil || *p.DryRun {
 				dr = []string{"All"}
@@ -188,7 +207,17 @@ func RegisterResources(reg *mcp.Registry, k *k8s.Clients) {
 				}
 			}
 			opts := metav1.DeleteOptions{DryRun: dr, GracePeriodSeconds: p.GracePeriodSeconds, PropagationPolicy: pp}
-			if err := ri.Delete(ctx, p.Name, opts); err != nil {
+			// a retried delete may find the object already gone from the first attempt
+			attempted := false
+			err = k8s.Retry(ctx, func() error {
+				err := ri.Delete(ctx, p.Name, opts)
+				if attempted && apierrors.IsNotFound(err) {
+					return nil
+				}
+				attempted = true
+				return err
+			})
+			if err != nil {
 				return nil, err
 			}
 			return map[string]any{"status": "Success"}, nil
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// readOnly wraps a side-effect free handler so the whole call is retried on
+// transient API errors (throttling, 5xx, connection resets). Mutating tools
+// must not use it; they retry individual idempotent calls with k8s.Retry.
+func readOnly(h mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var out any
+		err := k8s.Retry(ctx, func() error {
+			var err error
+			out, err = h(ctx, params)
+			return err
+		})
+		return out, err
+	}
+}
@@ -0,0 +1,598 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+	defaultRolloutWait  = 2 * time.Minute
+	maxRolloutWait      = 10 * time.Minute
+	rolloutPollInterval = 2 * time.Second
+)
+
+// revisionInfo is one entry of a rollout history: a ReplicaSet for
+// Deployments, a ControllerRevision for StatefulSets and DaemonSets.
+type revisionInfo struct {
+	Revision    int64     `json:"revision"`
+	Name        string    `json:"name"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	Images      []string  `json:"images"`
+	Created     time.Time `json:"created"`
+	Replicas    *int32    `json:"replicas,omitempty"`
+	Current     bool      `json:"current,omitempty"`
+	template    *corev1.PodTemplateSpec
+	patch       []byte
+}
+
+// rolloutState is the progress of a rollout, worded like kubectl rollout status.
+type rolloutState struct {
+	Done      bool   `json:"done"`
+	Failed    bool   `json:"failed,omitempty"`
+	TimedOut  bool   `json:"timedOut,omitempty"`
+	Message   string `json:"message"`
+	Desired   int32  `json:"desired"`
+	Updated   int32  `json:"updated"`
+	Ready     int32  `json:"ready"`
+	Available int32  `json:"available"`
+}
+
+func RegisterRollout(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "workloads-scale", Description: "Scale a Deployment or StatefulSet to N replicas", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "rollout-restart", Description: "Restart the pods of a Deployment, StatefulSet or DaemonSet with a rolling update", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "rollout-status", Description: "Wait for a rollout to complete or fail and report its progress", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "rollout-history", Description: "List the revisions of a Deployment, StatefulSet or DaemonSet", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "rollout-undo", Description: "Roll a Deployment, StatefulSet or DaemonSet back to a previous revision", DirectResult: true, Handler: notReady})
+		return
+	}
+	// workloads-scale
+	reg.Register(mcp.Tool{
+		Name:         "workloads-scale",
+		Description:  "Scale a Deployment or StatefulSet to N replicas",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("workloads-scale", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Kind, Name string
+				Replicas              *int32
+				DryRun                *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			kind, err := rolloutKind(p.Kind)
+			if err != nil {
+				return nil, err
+			}
+			if kind == "DaemonSet" {
+				return nil, errors.New("a DaemonSet runs one pod per eligible node and cannot be scaled; change its nodeSelector or tolerations instead")
+			}
+			if p.Replicas == nil || *p.Replicas < 0 {
+				return nil, errors.New("replicas is required and must not be negative")
+			}
+			if err := guardWorkload(ctx, k, "workloads-scale", kind, p.Namespace, p.Name, "scale"); err != nil {
+				return nil, err
+			}
+			var from int32
+			switch kind {
+			case "Deployment":
+				obj, err := k.Clientset.AppsV1().Deployments(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+				if err != nil {
+					return nil, err
+				}
+				from = replicasOrOne(obj.Spec.Replicas)
+			case "StatefulSet":
+				obj, err := k.Clientset.AppsV1().StatefulSets(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+				if err != nil {
+					return nil, err
+				}
+				from = replicasOrOne(obj.Spec.Replicas)
+			}
+			patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, *p.Replicas)
+			// setting a fixed count is idempotent
+			err = k8s.Retry(ctx, func() error {
+				return patchWorkload(ctx, k, kind, p.Namespace, p.Name, types.MergePatchType, []byte(patch), dryRun, "scale")
+			})
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{"kind": kind, "name": p.Name, "namespace": p.Namespace, "from": from, "to": *p.Replicas, "dryRun": dryRun}
+			if hpas := autoscalersFor(ctx, k, p.Namespace, kind, p.Name); len(hpas) > 0 {
+				out["warning"] = fmt.Sprintf("HorizontalPodAutoscaler %s manages this %s and will override the replica count", strings.Join(hpas, ", "), kind)
+			}
+			return out, nil
+		},
+	})
+
+	// rollout-restart
+	reg.Register(mcp.Tool{
+		Name:         "rollout-restart",
+		Description:  "Restart the pods of a Deployment, StatefulSet or DaemonSet with a rolling update",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("rollout-restart", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Kind, Name string
+				DryRun                *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			kind, err := rolloutKind(p.Kind)
+			if err != nil {
+				return nil, err
+			}
+			if err := guardWorkload(ctx, k, "rollout-restart", kind, p.Namespace, p.Name, ""); err != nil {
+				return nil, err
+			}
+			if err := checkNotPaused(ctx, k, kind, p.Namespace, p.Name); err != nil {
+				return nil, err
+			}
+			// the same annotation kubectl rollout restart sets
+			now := time.Now().UTC().Format(time.RFC3339)
+			patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, restartedAtAnnotation, now)
+			if err := patchWorkload(ctx, k, kind, p.Namespace, p.Name, types.StrategicMergePatchType, []byte(patch), dryRun); err != nil {
+				return nil, err
+			}
+			return map[string]any{"kind": kind, "name": p.Name, "namespace": p.Namespace, "restartedAt": now, "dryRun": dryRun}, nil
+		},
+	})
+
+	// rollout-status
+	reg.Register(mcp.Tool{
+		Name:         "rollout-status",
+		Description:  "Wait for a rollout to complete or fail and report its progress",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("rollout-status", 10, 5)
+			k := k.Current()
+			var p struct {
+				Namespace, Kind, Name string
+				Wait                  *bool // default true
+				TimeoutSeconds        int
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			kind, err := rolloutKind(p.Kind)
+			if err != nil {
+				return nil, err
+			}
+			wait := p.Wait == nil || *p.Wait
+			timeout := defaultRolloutWait
+			if p.TimeoutSeconds > 0 {
+				timeout = min(time.Duration(p.TimeoutSeconds)*time.Second, maxRolloutWait)
+			}
+			deadline := time.Now().Add(timeout)
+			for {
+				var st rolloutState
+				err := k8s.Retry(ctx, func() error {
+					var err error
+					st, err = rolloutStatus(ctx, k, kind, p.Namespace, p.Name)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				if st.Done || st.Failed || !wait {
+					return st, nil
+				}
+				if time.Now().After(deadline) {
+					st.TimedOut = true
+					return st, nil
+				}
+				mcp.ReportProgress(ctx, float64(st.Updated), float64(st.Desired), st.Message)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(rolloutPollInterval):
+				}
+			}
+		},
+	})
+
+	// rollout-history
+	reg.Register(mcp.Tool{
+		Name:         "rollout-history",
+		Description:  "List the revisions of a Deployment, StatefulSet or DaemonSet",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("rollout-history", 10, 5)
+			k := k.Current()
+			var p struct{ Namespace, Kind, Name string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			kind, err := rolloutKind(p.Kind)
+			if err != nil {
+				return nil, err
+			}
+			revs, err := rolloutHistory(ctx, k, kind, p.Namespace, p.Name)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"kind": kind, "name": p.Name, "namespace": p.Namespace, "revisions": revs}, nil
+		}),
+	})
+
+	// rollout-undo
+	reg.Register(mcp.Tool{
+		Name:         "rollout-undo",
+		Description:  "Roll a Deployment, StatefulSet or DaemonSet back to a previous revision",
+		DirectResult: true,
+		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("rollout-undo", 5, 2)
+			k := k.Current()
+			var p struct {
+				Namespace, Kind, Name string
+				ToRevision            int64 // 0 = the previous revision
+				DryRun                *bool
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			dryRun := p.DryRun == nil || *p.DryRun
+			kind, err := rolloutKind(p.Kind)
+			if err != nil {
+				return nil, err
+			}
+			if err := guardWorkload(ctx, k, "rollout-undo", kind, p.Namespace, p.Name, ""); err != nil {
+				return nil, err
+			}
+			if err := checkNotPaused(ctx, k, kind, p.Namespace, p.Name); err != nil {
+				return nil, err
+			}
+			revs, err := rolloutHistory(ctx, k, kind, p.Namespace, p.Name)
+			if err != nil {
+				return nil, err
+			}
+			target, err := undoTarget(revs, p.ToRevision)
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{"kind": kind, "name": p.Name, "namespace": p.Namespace, "toRevision": target.Revision, "images": target.Images, "dryRun": dryRun}
+			if target.Current {
+				out["skipped"] = fmt.Sprintf("revision %d is already the current template", target.Revision)
+				return out, nil
+			}
+			pt, patch := types.StrategicMergePatchType, target.patch
+			if kind == "Deployment" {
+				// like kubectl: put the ReplicaSet's template back, minus the
+				// hash label the controller adds
+				tmpl := target.template.DeepCopy()
+				delete(tmpl.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+				if patch, err = json.Marshal([]map[string]any{{"op": "replace", "path": "/spec/template", "value": tmpl}}); err != nil {
+					return nil, err
+				}
+				pt = types.JSONPatchType
+			}
+			if err := patchWorkload(ctx, k, kind, p.Namespace, p.Name, pt, patch, dryRun); err != nil {
+				return nil, err
+			}
+			return out, nil
+		},
+	})
+}
+
+// rolloutKind accepts the workload kinds the rollout tools handle.
+func rolloutKind(kind string) (string, error) {
+	switch c := canonicalKind(kind); c {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return c, nil
+	}
+	return "", fmt.Errorf("unsupported kind %q (use Deployment, StatefulSet or DaemonSet)", kind)
+}
+
+// guardWorkload runs the mutating guards for a patch of an apps/v1 workload.
+func guardWorkload(ctx context.Context, k *k8s.Clients, tool, kind, ns, name, subresource string) error {
+	if err := authz.EnforceMutating(tool, ns, kind); err != nil {
+		return err
+	}
+	return authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: "patch", Group: "apps", Resource: strings.ToLower(kind) + "s", Subresource: subresource, Name: name, Namespace: ns})
+}
+
+func patchWorkload(ctx context.Context, k *k8s.Clients, kind, ns, name string, pt types.PatchType, data []byte, dryRun bool, subresources ...string) error {
+	opts := metav1.PatchOptions{}
+	if dryRun {
+		opts.DryRun = []string{"All"}
+	}
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = k.Clientset.AppsV1().Deployments(ns).Patch(ctx, name, pt, data, opts, subresources...)
+	case "StatefulSet":
+		_, err = k.Clientset.AppsV1().StatefulSets(ns).Patch(ctx, name, pt, data, opts, subresources...)
+	case "DaemonSet":
+		_, err = k.Clientset.AppsV1().DaemonSets(ns).Patch(ctx, name, pt, data, opts, subresources...)
+	}
+	return err
+}
+
+// checkNotPaused refuses to change a paused Deployment, as kubectl does:
+// the change would not roll out until it is resumed.
+func checkNotPaused(ctx context.Context, k *k8s.Clients, kind, ns, name string) error {
+	if kind != "Deployment" {
+		return nil
+	}
+	d, err := k.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if d.Spec.Paused {
+		return fmt.Errorf("deployment %s is paused; resume it (spec.paused=false) first", name)
+	}
+	return nil
+}
+
+func replicasOrOne(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+// autoscalersFor names the HPAs targeting a workload; errors are ignored
+// since this only feeds a warning.
+func autoscalersFor(ctx context.Context, k *k8s.Clients, ns, kind, name string) []string {
+	list, err := k.Clientset.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, h := range list.Items {
+		if h.Spec.ScaleTargetRef.Kind == kind && h.Spec.ScaleTargetRef.Name == name {
+			out = append(out, h.Name)
+		}
+	}
+	return out
+}
+
+// rolloutStatus mirrors the checks of kubectl rollout status.
+func rolloutStatus(ctx context.Context, k *k8s.Clients, kind, ns, name string) (rolloutState, error) {
+	var st rolloutState
+	switch kind {
+	case "Deployment":
+		d, err := k.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return st, err
+		}
+		st.Desired, st.Updated, st.Ready, st.Available = replicasOrOne(d.Spec.Replicas), d.Status.UpdatedReplicas, d.Status.ReadyReplicas, d.Status.AvailableReplicas
+		if d.Generation > d.Status.ObservedGeneration {
+			st.Message = "waiting for the deployment spec update to be observed"
+			return st, nil
+		}
+		for _, c := range d.Status.Conditions {
+			if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+				st.Failed, st.Message = true, fmt.Sprintf("deployment %q exceeded its progress deadline: %s", name, c.Message)
+				return st, nil
+			}
+		}
+		switch {
+		case st.Updated < st.Desired:
+			st.Message = fmt.Sprintf("waiting for rollout to finish: %d of %d new replicas have been updated", st.Updated, st.Desired)
+		case d.Status.Replicas > st.Updated:
+			st.Message = fmt.Sprintf("waiting for rollout to finish: %d old replicas are pending termination", d.Status.Replicas-st.Updated)
+		case st.Available < st.Updated:
+			st.Message = fmt.Sprintf("waiting for rollout to finish: %d of %d updated replicas are available", st.Available, st.Updated)
+		default:
+			st.Done, st.Message = true, fmt.Sprintf("deployment %q successfully rolled out", name)
+		}
+	case "StatefulSet":
+		s, err := k.Clientset.AppsV1().StatefulSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return st, err
+		}
+		if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return st, errors.New("rollout status is only available for the RollingUpdate strategy; with OnDelete, pods update only when deleted")
+		}
+		st.Desired, st.Updated, st.Ready, st.Available = replicasOrOne(s.Spec.Replicas), s.Status.UpdatedReplicas, s.Status.ReadyReplicas, s.Status.AvailableReplicas
+		if s.Status.ObservedGeneration == 0 || s.Generation > s.Status.ObservedGeneration {
+			st.Message = "waiting for the statefulset spec update to be observed"
+			return st, nil
+		}
+		var partition int32
+		if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+			partition = *ru.Partition
+		}
+		switch {
+		case st.Ready < st.Desired:
+			st.Message = fmt.Sprintf("waiting for %d pods to be ready", st.Desired-st.Ready)
+		case partition > 0 && st.Updated < st.Desired-partition:
+			st.Message = fmt.Sprintf("waiting for partitioned roll out to finish: %d of %d new pods have been updated", st.Updated, st.Desired-partition)
+		case partition > 0:
+			st.Done, st.Message = true, fmt.Sprintf("partitioned roll out complete: %d new pods have been updated", st.Updated)
+		case s.Status.UpdateRevision != s.Status.CurrentRevision:
+			st.Message = fmt.Sprintf("waiting for statefulset rolling update to complete: %d pods at revision %s", st.Updated, s.Status.UpdateRevision)
+		default:
+			st.Done, st.Message = true, fmt.Sprintf("statefulset rolling update complete: %d pods at revision %s", s.Status.CurrentReplicas, s.Status.CurrentRevision)
+		}
+	case "DaemonSet":
+		d, err := k.Clientset.AppsV1().DaemonSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return st, err
+		}
+		if d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return st, errors.New("rollout status is only available for the RollingUpdate strategy; with OnDelete, pods update only when deleted")
+		}
+		st.Desired, st.Updated, st.Ready, st.Available = d.Status.DesiredNumberScheduled, d.Status.UpdatedNumberScheduled, d.Status.NumberReady, d.Status.NumberAvailable
+		if d.Generation > d.Status.ObservedGeneration {
+			st.Message = "waiting for the daemon set spec update to be observed"
+			return st, nil
+		}
+		switch {
+		case st.Updated < st.Desired:
+			st.Message = fmt.Sprintf("waiting for rollout to finish: %d of %d new pods have been updated", st.Updated, st.Desired)
+		case st.Available < st.Desired:
+			st.Message = fmt.Sprintf("waiting for rollout to finish: %d of %d updated pods are available", st.Available, st.Desired)
+		default:
+			st.Done, st.Message = true, fmt.Sprintf("daemon set %q successfully rolled out", name)
+		}
+	}
+	return st, nil
+}
+
+// rolloutHistory lists a workload's revisions, oldest first.
+func rolloutHistory(ctx context.Context, k *k8s.Clients, kind, ns, name string) ([]revisionInfo, error) {
+	var owner metav1.Object
+	var selector *metav1.LabelSelector
+	var current string
+	switch kind {
+	case "Deployment":
+		d, err := k.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		sel, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		list, err := k.Clientset.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+		if err != nil {
+			return nil, err
+		}
+		var revs []revisionInfo
+		for i := range list.Items {
+			rs := &list.Items[i]
+			if !metav1.IsControlledBy(rs, d) {
+				continue
+			}
+			rev, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+			revs = append(revs, revisionInfo{
+				Revision:    rev,
+				Name:        rs.Name,
+				ChangeCause: rs.Annotations[changeCauseAnnotation],
+				Images:      templateImages(&rs.Spec.Template),
+				Created:     rs.CreationTimestamp.Time,
+				Replicas:    rs.Spec.Replicas,
+				Current:     rs.Annotations[revisionAnnotation] == d.Annotations[revisionAnnotation],
+				template:    &rs.Spec.Template,
+			})
+		}
+		sort.Slice(revs, func(i, j int) bool { return revs[i].Revision < revs[j].Revision })
+		return revs, nil
+	case "StatefulSet":
+		s, err := k.Clientset.AppsV1().StatefulSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, selector, current = s, s.Spec.Selector, s.Status.UpdateRevision
+	case "DaemonSet":
+		d, err := k.Clientset.AppsV1().DaemonSets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, selector = d, d.Spec.Selector
+	}
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := k.Clientset.AppsV1().ControllerRevisions(ns).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+	if err != nil {
+		return nil, err
+	}
+	var revs []revisionInfo
+	for i := range list.Items {
+		cr := &list.Items[i]
+		if !metav1.IsControlledBy(cr, owner) {
+			continue
+		}
+		// the data is a patch that replaces spec.template
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		_ = json.Unmarshal(cr.Data.Raw, &data)
+		revs = append(revs, revisionInfo{
+			Revision:    cr.Revision,
+			Name:        cr.Name,
+			ChangeCause: cr.Annotations[changeCauseAnnotation],
+			Images:      templateImages(&data.Spec.Template),
+			Created:     cr.CreationTimestamp.Time,
+			Current:     cr.Name == current,
+			patch:       cr.Data.Raw,
+		})
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Revision < revs[j].Revision })
+	if current == "" && len(revs) > 0 {
+		// a DaemonSet's newest revision is the one it rolls out
+		revs[len(revs)-1].Current = true
+	}
+	return revs, nil
+}
+
+// undoTarget picks the revision to roll back to; 0 means the one before
+// the current revision.
+func undoTarget(revs []revisionInfo, to int64) (*revisionInfo, error) {
+	if len(revs) == 0 {
+		return nil, errors.New("no rollout history found")
+	}
+	if to > 0 {
+		var known []string
+		for i := range revs {
+			if revs[i].Revision == to {
+				return &revs[i], nil
+			}
+			known = append(known, strconv.FormatInt(revs[i].Revision, 10))
+		}
+		return nil, fmt.Errorf("revision %d not found (available: %s)", to, strings.Join(known, ", "))
+	}
+	for i := len(revs) - 1; i > 0; i-- {
+		if revs[i].Current {
+			return &revs[i-1], nil
+		}
+	}
+	return nil, errors.New("no previous revision to roll back to")
+}
+
+func templateImages(t *corev1.PodTemplateSpec) []string {
+	images := []string{}
+	for _, c := range t.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
@@ -0,0 +1,394 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// nodeFit is the per-node verdict of pods-why-pending.
+type nodeFit struct {
+	Node    string   `json:"node"`
+	Fits    bool     `json:"fits"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// pendingVolume is the binding state of a PVC the pending pod mounts.
+type pendingVolume struct {
+	Claim        string `json:"claim"`
+	Status       string `json:"status"`
+	StorageClass string `json:"storageClass,omitempty"`
+	BindingMode  string `json:"bindingMode,omitempty"`
+	Volume       string `json:"volume,omitempty"`
+	Note         string `json:"note,omitempty"`
+	// pv is the bound PersistentVolume, used for node affinity checks
+	pv *corev1.PersistentVolume
+}
+
+func RegisterScheduling(reg *mcp.Registry, k *k8s.Clients) {
+	if k == nil {
+		notReady := func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("Kubernetes client not initialized yet")
+		}
+		reg.Register(mcp.Tool{Name: "pods-why-pending", Description: "Explain why a pod is not scheduled: per-node taints, selectors, affinity, spread, resources and volumes", DirectResult: true, Handler: notReady})
+		return
+	}
+	// pods-why-pending
+	reg.Register(mcp.Tool{
+		Name:         "pods-why-pending",
+		Description:  "Explain why a pod is not scheduled: per-node taints, selectors, affinity, spread, resources and volumes",
+		DirectResult: true,
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
+			_ = authz.RateLimit("pods-why-pending", 10, 5)
+			k := k.Current()
+			var p struct{ Name, Namespace string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			pod, err := k.Clientset.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]any{"pod": pod.Name, "namespace": pod.Namespace, "phase": pod.Status.Phase}
+			if pod.Spec.NodeName != "" {
+				out["scheduled"] = true
+				out["node"] = pod.Spec.NodeName
+				return out, nil
+			}
+			out["scheduled"] = false
+			out["schedulerName"] = pod.Spec.SchedulerName
+			for _, c := range pod.Status.Conditions {
+				if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+					out["condition"] = map[string]string{"reason": c.Reason, "message": c.Message}
+				}
+			}
+			if events, err := listEvents(ctx, k, pod.Namespace, eventFilter{Kind: "Pod", Name: pod.Name, Reason: "FailedScheduling"}); err == nil {
+				var msgs []eventRecord
+				for _, e := range events {
+					if e.Kind == "Pod" && e.Name == pod.Name && e.Reason == "FailedScheduling" {
+						msgs = append(msgs, e)
+					}
+				}
+				sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].LastSeen.After(msgs[j].LastSeen) })
+				if len(msgs) > 5 {
+					msgs = msgs[:5]
+				}
+				out["schedulerEvents"] = msgs
+			}
+			reqs, _ := podRequestsAndLimits(pod)
+			requests := map[string]string{}
+			for name, q := range reqs {
+				requests[string(name)] = q.String()
+			}
+			out["requests"] = requests
+
+			volumes, volumeIssues := pendingVolumes(ctx, k, pod)
+			if len(volumes) > 0 {
+				out["volumes"] = volumes
+			}
+			nodes, err := k.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			allPods, err := k.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			fits := evaluateNodes(pod, reqs, nodes.Items, allPods.Items, volumes)
+			fitting := 0
+			for _, f := range fits {
+				if f.Fits {
+					fitting++
+				}
+			}
+			out["nodes"] = fits
+			summary := fmt.Sprintf("%d of %d nodes fit the pod", fitting, len(fits))
+			if len(volumeIssues) > 0 {
+				summary += "; " + strings.Join(volumeIssues, "; ")
+			}
+			if fitting > 0 && len(volumeIssues) == 0 {
+				summary += " by these checks; inter-pod affinity and scheduler plugins are not evaluated, see schedulerEvents"
+			}
+			out["summary"] = summary
+			return out, nil
+		}),
+	})
+}
+
+// pendingVolumes reports the PVCs of pod and returns blocking issues.
+func pendingVolumes(ctx context.Context, k *k8s.Clients, pod *corev1.Pod) ([]pendingVolume, []string) {
+	var out []pendingVolume
+	var issues []string
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		pv := pendingVolume{Claim: v.PersistentVolumeClaim.ClaimName}
+		pvc, err := k.Clientset.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, pv.Claim, metav1.GetOptions{})
+		if err != nil {
+			pv.Status = "Missing"
+			if !apierrors.IsNotFound(err) {
+				pv.Status = "Unknown"
+			}
+			pv.Note = err.Error()
+			issues = append(issues, "PVC "+pv.Claim+" cannot be read: "+err.Error())
+			out = append(out, pv)
+			continue
+		}
+		pv.Status, pv.Volume = string(pvc.Status.Phase), pvc.Spec.VolumeName
+		if pvc.Spec.StorageClassName != nil {
+			pv.StorageClass = *pvc.Spec.StorageClassName
+		}
+		var sc *storagev1.StorageClass
+		if pv.StorageClass != "" {
+			sc, _ = k.Clientset.StorageV1().StorageClasses().Get(ctx, pv.StorageClass, metav1.GetOptions{})
+		}
+		if sc != nil && sc.VolumeBindingMode != nil {
+			pv.BindingMode = string(*sc.VolumeBindingMode)
+		}
+		switch {
+		case pvc.Status.Phase == corev1.ClaimBound && pv.Volume != "":
+			pv.pv, _ = k.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Volume, metav1.GetOptions{})
+		case pv.BindingMode == string(storagev1.VolumeBindingWaitForFirstConsumer):
+			pv.Note = "binds once the pod is scheduled (WaitForFirstConsumer)"
+		case pv.StorageClass != "" && sc == nil:
+			pv.Note = "storage class " + pv.StorageClass + " does not exist"
+			issues = append(issues, "PVC "+pv.Claim+" uses missing storage class "+pv.StorageClass)
+		default:
+			pv.Note = "claim is not bound; check the provisioner and events for the PVC"
+			issues = append(issues, "PVC "+pv.Claim+" is "+pv.Status)
+		}
+		out = append(out, pv)
+	}
+	return out, issues
+}
+
+// evaluateNodes checks each node the way the default scheduler's filters
+// do: readiness, cordon, taints, node selector and affinity, volume node
+// affinity, free resources and topology spread.
+func evaluateNodes(pod *corev1.Pod, reqs corev1.ResourceList, nodes []corev1.Node, pods []corev1.Pod, volumes []pendingVolume) []nodeFit {
+	used := map[string]corev1.ResourceList{}
+	counts := map[string]int64{}
+	for i := range pods {
+		other := &pods[i]
+		if other.Spec.NodeName == "" || other.Status.Phase == corev1.PodSucceeded || other.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		r, _ := podRequestsAndLimits(other)
+		if used[other.Spec.NodeName] == nil {
+			used[other.Spec.NodeName] = corev1.ResourceList{}
+		}
+		addResources(used[other.Spec.NodeName], r)
+		counts[other.Spec.NodeName]++
+	}
+	out := make([]nodeFit, 0, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		var reasons []string
+		if strings.HasPrefix(nodeStatus(node), "NotReady") || strings.HasPrefix(nodeStatus(node), "Unknown") {
+			reasons = append(reasons, "node is not Ready")
+		}
+		if node.Spec.Unschedulable && !toleratesTaint(pod, &corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}) {
+			reasons = append(reasons, "node is cordoned (unschedulable)")
+		}
+		for j := range node.Spec.Taints {
+			t := &node.Spec.Taints[j]
+			if t.Effect == corev1.TaintEffectPreferNoSchedule || toleratesTaint(pod, t) {
+				continue
+			}
+			reasons = append(reasons, "untolerated taint "+t.ToString())
+		}
+		for key, want := range pod.Spec.NodeSelector {
+			if got, ok := node.Labels[key]; !ok || got != want {
+				reasons = append(reasons, fmt.Sprintf("nodeSelector %s=%s does not match (node has %q)", key, want, node.Labels[key]))
+			}
+		}
+		if a := pod.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+			if !matchesNodeSelector(a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, node) {
+				reasons = append(reasons, "required node affinity does not match")
+			}
+		}
+		for _, v := range volumes {
+			if v.pv != nil && v.pv.Spec.NodeAffinity != nil && v.pv.Spec.NodeAffinity.Required != nil && !matchesNodeSelector(v.pv.Spec.NodeAffinity.Required, node) {
+				reasons = append(reasons, "volume node affinity conflict (PV "+v.pv.Name+" for claim "+v.Claim+")")
+			}
+		}
+		reasons = append(reasons, insufficientResources(reqs, node, used[node.Name], counts[node.Name])...)
+		reasons = append(reasons, spreadViolations(pod, node, nodes, pods)...)
+		out = append(out, nodeFit{Node: node.Name, Fits: len(reasons) == 0, Reasons: reasons})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Reasons) < len(out[j].Reasons) })
+	return out
+}
+
+func toleratesTaint(pod *corev1.Pod, taint *corev1.Taint) bool {
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeSelector evaluates required node affinity: terms are ORed and
+// the requirements inside a term are ANDed.
+func matchesNodeSelector(sel *corev1.NodeSelector, node *corev1.Node) bool {
+	for _, term := range sel.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		ok := true
+		for _, req := range term.MatchExpressions {
+			v, has := node.Labels[req.Key]
+			ok = ok && matchesRequirement(req, v, has)
+		}
+		for _, req := range term.MatchFields {
+			ok = ok && req.Key == "metadata.name" && matchesRequirement(req, node.Name, true)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRequirement(req corev1.NodeSelectorRequirement, value string, has bool) bool {
+	in := func() bool {
+		for _, v := range req.Values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return has && in()
+	case corev1.NodeSelectorOpNotIn:
+		return !has || !in()
+	case corev1.NodeSelectorOpExists:
+		return has
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !has
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !has || len(req.Values) != 1 {
+			return false
+		}
+		got, err1 := strconv.ParseInt(value, 10, 64)
+		want, err2 := strconv.ParseInt(req.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if req.Operator == corev1.NodeSelectorOpGt {
+			return got > want
+		}
+		return got < want
+	}
+	return false
+}
+
+// insufficientResources compares the pod's requests with what the node has
+// left after the requests of the pods already bound to it.
+func insufficientResources(reqs corev1.ResourceList, node *corev1.Node, used corev1.ResourceList, podCount int64) []string {
+	var out []string
+	if maxPods, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && podCount+1 > maxPods.Value() {
+		out = append(out, fmt.Sprintf("too many pods (%d of %d)", podCount, maxPods.Value()))
+	}
+	names := make([]string, 0, len(reqs))
+	for name := range reqs {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		name := corev1.ResourceName(n)
+		want := reqs[name]
+		if want.IsZero() {
+			continue
+		}
+		alloc, ok := node.Status.Allocatable[name]
+		if !ok {
+			out = append(out, fmt.Sprintf("node does not offer %s", name))
+			continue
+		}
+		free := alloc.DeepCopy()
+		free.Sub(used[name])
+		if want.Cmp(free) > 0 {
+			if free.Sign() < 0 {
+				free = resource.Quantity{}
+			}
+			out = append(out, fmt.Sprintf("insufficient %s: requests %s, free %s of %s allocatable", name, want.String(), free.String(), alloc.String()))
+		}
+	}
+	return out
+}
+
+// spreadViolations checks DoNotSchedule topology spread constraints: placing
+// the pod on node must not push its domain more than maxSkew above the
+// least-loaded domain.
+func spreadViolations(pod *corev1.Pod, node *corev1.Node, nodes []corev1.Node, pods []corev1.Pod) []string {
+	var out []string
+	for _, c := range pod.Spec.TopologySpreadConstraints {
+		if c.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		domain, ok := node.Labels[c.TopologyKey]
+		if !ok {
+			out = append(out, "missing topology label "+c.TopologyKey+" required by topologySpreadConstraints")
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(c.LabelSelector)
+		if err != nil {
+			continue
+		}
+		nodeDomain := map[string]string{}
+		counts := map[string]int32{}
+		for i := range nodes {
+			if d, ok := nodes[i].Labels[c.TopologyKey]; ok {
+				nodeDomain[nodes[i].Name] = d
+				counts[d] += 0
+			}
+		}
+		for i := range pods {
+			other := &pods[i]
+			if other.Namespace != pod.Namespace || other.Spec.NodeName == "" || !sel.Matches(labels.Set(other.Labels)) {
+				continue
+			}
+			if d, ok := nodeDomain[other.Spec.NodeName]; ok {
+				counts[d]++
+			}
+		}
+		minCount := int32(-1)
+		for _, n := range counts {
+			if minCount < 0 || n < minCount {
+				minCount = n
+			}
+		}
+		// the incoming pod only adds to its domain if the selector matches it
+		self := int32(0)
+		if sel.Matches(labels.Set(pod.Labels)) {
+			self = 1
+		}
+		if skew := counts[domain] + self - minCount; skew > c.MaxSkew {
+			out = append(out, fmt.Sprintf("topology spread on %s: %s=%s would have skew %d (maxSkew %d)", c.TopologyKey, c.TopologyKey, domain, skew, c.MaxSkew))
+		}
+	}
+	return out
+}
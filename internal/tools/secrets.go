@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -28,8 +29,9 @@ func RegisterSecrets(reg *mcp.Registry, k *k8s.Clients) {
 		Name:         "secrets-get",
 		Description:  "Get a secret (redacted by default)",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("secrets-get", 10, 5)
+			k := k.Current()
 			var p struct {
 				Namespace, Name string
 				Keys            []string
@@ -60,7 +62,7 @@ func RegisterSecrets(reg *mcp.Registry, k *k8s.Clients) {
 			}
 			out := map[string]any{"type": s.Type, "data": data}
 			return out, nil
-		},
+		}),
 	})
 
 	// secrets-set
@@ -70,6 +72,7 @@ func RegisterSecrets(reg *mcp.Registry, k *k8s.Clients) {
 		DirectResult: true,
 		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("secrets-set", 10, 5)
+			k := k.Current()
 			var p struct {
 				Namespace, Name string
 				Data            map[string]string
@@ -84,7 +87,18 @@ func RegisterSecrets(reg *mcp.Registry, k *k8s.Clients) {
 			if err := authz.EnforceMutating("secrets-set", p.Namespace, "Secret"); err != nil {
 				return nil, err
 			}
-			existing, _ := k.Clientset.CoreV1().Secrets(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			existing, err := k.Clientset.CoreV1().Secrets(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				// client-go returns an empty object alongside the error
+				existing = nil
+			}
+			verb := "update"
+			if existing == nil {
+				verb = "create"
+			}
+			if err := authz.Preflight(ctx, k.Clientset, authorizationv1.ResourceAttributes{Verb: verb, Resource: "secrets", Name: p.Name, Namespace: p.Namespace}); err != nil {
+				return nil, err
+			}
 			data := map[string][]byte{}
 			for k, v := range p.Data {
 				if p.Base64Encoded != nil && *p.Base64Encoded {
@@ -116,7 +130,14 @@ func RegisterSecrets(reg *mcp.Registry, k *k8s.Clients) {
 				}
 				return map[string]any{"created": true, "name": res.Name, "keys": keysOf(data)}, nil
 			}
-			res, err := k.Clientset.CoreV1().Secrets(p.Namespace).Update(ctx, sec, metav1.UpdateOptions{DryRun: dr})
+			// sec carries no resourceVersion, so the update is unconditional and safe to retry;
+			// create is not retried because a repeat would fail with AlreadyExists
+			var res *corev1.Secret
+			err = k8s.Retry(ctx, func() error {
+				var err error
+				res, err = k.Clientset.CoreV1().Secrets(p.Namespace).Update(ctx, sec, metav1.UpdateOptions{DryRun: dr})
+				return err
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"log/slog"
+
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// RegisterAll registers every tool group; a nil k registers placeholders.
+func RegisterAll(reg *mcp.Registry, k *k8s.Clients, logger *slog.Logger) {
+	RegisterCluster(reg, k, logger)
+	RegisterNamespaces(reg, k)
+	RegisterWorkloads(reg, k)
+	RegisterLogs(reg, k)
+	RegisterPortForward(reg, k)
+	RegisterCopy(reg, k)
+	RegisterRollout(reg, k)
+	RegisterBatch(reg, k)
+	RegisterScheduling(reg, k)
+	RegisterDiagnose(reg, k)
+	RegisterNodes(reg, k)
+	RegisterEvents(reg, k)
+	RegisterMetrics(reg, k)
+	RegisterResources(reg, k)
+	RegisterDiscovery(reg, k)
+	RegisterSecrets(reg, k)
+	RegisterAuth(reg, k)
+}
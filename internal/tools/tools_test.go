@@ -0,0 +1,1440 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/example/mcp-k8s-server-go/internal/authz"
+	"github.com/example/mcp-k8s-server-go/pkg/k8s"
+	"github.com/example/mcp-k8s-server-go/pkg/mcp"
+)
+
+// harness drives registered tools through the MCP server over NDJSON,
+// backed by client-go fake clientsets seeded with the same objects.
+type harness struct {
+	t   *testing.T
+	srv *mcp.Server
+	kc  *k8s.Clients
+	cs  *fake.Clientset
+	// metrics serves metrics.k8s.io; seed it with addMetrics
+	metrics *dynamicfake.FakeDynamicClient
+	// denied holds "verb resource" pairs (e.g. "create pods/exec") that
+	// SelfSubjectAccessReviews reject; everything else is allowed
+	denied map[string]bool
+}
+
+func newHarness(t *testing.T, objs ...runtime.Object) *harness {
+	t.Helper()
+	t.Setenv("MCP_K8S_RETRY_MAX", "0")
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	cs := fake.NewClientset(objs...)
+	dyn := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, objs...)
+	// the fake tracker cannot server-side apply unstructured objects, so
+	// answer apply patches with the applied manifest itself
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pa := action.(k8stesting.PatchAction)
+		if pa.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj, err := k8s.DecodeYAMLToUnstructured(pa.GetPatch())
+		return true, obj, err
+	})
+	cs.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", ShortNames: []string{"po"}, Kind: "Pod", Namespaced: true, Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "configmaps", ShortNames: []string{"cm"}, Kind: "ConfigMap", Namespaced: true, Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			{Name: "nodes", ShortNames: []string{"no"}, Kind: "Node", Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", ShortNames: []string{"deploy"}, Kind: "Deployment", Namespaced: true, Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		}},
+		{GroupVersion: "metrics.k8s.io/v1beta1", APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "PodMetrics", Namespaced: true, Verbs: []string{"get", "list"}},
+			{Name: "nodes", Kind: "NodeMetrics", Verbs: []string{"get", "list"}},
+		}},
+	}
+	metricsScheme := runtime.NewScheme()
+	for _, kind := range []string{"PodMetrics", "NodeMetrics"} {
+		gvk := podMetricsGVR.GroupVersion().WithKind(kind)
+		metricsScheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		metricsScheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(kind+"List"), &unstructured.UnstructuredList{})
+	}
+	metrics := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(metricsScheme, map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList", nodeMetricsGVR: "NodeMetricsList"})
+	kc := k8s.NewForClients(logger, cs, metricsDynamic{Interface: dyn, metrics: metrics}, testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme))
+	kc.OpenAPI = openapitest.NewEmbeddedFileClient()
+	srv := mcp.NewServer(logger)
+	RegisterAll(srv.Registry(), kc, logger)
+	h := &harness{t: t, srv: srv, kc: kc, cs: cs, metrics: metrics, denied: map[string]bool{}}
+	cs.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !h.denied[attrs.Verb+" "+authz.DescribeResource(*attrs)]
+		review.Status.Denied = !review.Status.Allowed
+		return true, review, nil
+	})
+	// the API server deletes a pod once its eviction is admitted
+	cs.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		return true, nil, cs.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	return h
+}
+
+// metricsDynamic routes metrics.k8s.io to a fake registered with its list
+// kinds; the resource names (pods, nodes) cannot be guessed from the kinds.
+type metricsDynamic struct {
+	dynamic.Interface
+	metrics dynamic.Interface
+}
+
+func (d metricsDynamic) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	if gvr.Group == podMetricsGVR.Group {
+		return d.metrics.Resource(gvr)
+	}
+	return d.Interface.Resource(gvr)
+}
+
+// addMetrics seeds PodMetrics ("ns/name" with container=cpu/memory usage)
+// and NodeMetrics (plain node name) objects.
+func (h *harness) addMetrics(usage map[string]map[string][2]string) {
+	h.t.Helper()
+	for key, containers := range usage {
+		obj := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "metrics.k8s.io/v1beta1", "window": "30s"}}
+		ns, name, namespaced := strings.Cut(key, "/")
+		gvr := nodeMetricsGVR
+		if namespaced {
+			gvr = podMetricsGVR
+			obj.SetKind("PodMetrics")
+			obj.SetNamespace(ns)
+			obj.SetName(name)
+			var list []any
+			for c, u := range containers {
+				list = append(list, map[string]any{"name": c, "usage": map[string]any{"cpu": u[0], "memory": u[1]}})
+			}
+			obj.Object["containers"] = list
+		} else {
+			obj.SetKind("NodeMetrics")
+			obj.SetName(ns)
+			u := containers[""]
+			obj.Object["usage"] = map[string]any{"cpu": u[0], "memory": u[1]}
+		}
+		if err := h.metrics.Tracker().Create(gvr, obj, obj.GetNamespace()); err != nil {
+			h.t.Fatal(err)
+		}
+	}
+}
+
+// call invokes a tool via tools/call and returns its JSON payload (or error
+// text) and whether the result was flagged as an error.
+func (h *harness) call(name string, args any) (json.RawMessage, bool) {
+	h.t.Helper()
+	a, err := json.Marshal(args)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	req, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{"name": name, "arguments": json.RawMessage(a)}})
+	var out bytes.Buffer
+	if err := h.srv.Run(context.Background(), bytes.NewReader(append(req, '\n')), &out); err != nil {
+		h.t.Fatalf("%s: run: %v", name, err)
+	}
+	var resp struct {
+		Result struct {
+			Content []struct {
+				Text string          `json:"text"`
+				Data json.RawMessage `json:"data"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	// the response is the last line; notifications may come before it
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if err := json.Unmarshal(lines[len(lines)-1], &resp); err != nil || len(resp.Result.Content) != 1 {
+		h.t.Fatalf("%s: bad response %q: %v", name, out.String(), err)
+	}
+	c := resp.Result.Content[0]
+	if resp.Result.IsError {
+		return json.RawMessage(c.Text), true
+	}
+	return c.Data, false
+}
+
+func testObjects() []runtime.Object {
+	return []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "web", Effect: corev1.TaintEffectNoSchedule}}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+				},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi"), corev1.ResourcePods: resource.MustParse("110")},
+				Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi"), corev1.ResourcePods: resource.MustParse("110")},
+				NodeInfo:    corev1.NodeSystemInfo{KubeletVersion: "v1.34.0", OSImage: "Ubuntu 24.04"},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{Name: "app", Image: "nginx:1.27", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			}}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 2}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent-x1", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: ptrBool(true)}}},
+			Spec:       corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{Name: "agent", Image: "agent:1"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-0.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0", Namespace: "default"},
+			Type:           corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting failed container",
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"}, Data: map[string][]byte{"password": []byte("hunter2")}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"a": "1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default"}},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "default"},
+			Status:     corev1.ResourceQuotaStatus{Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}},
+		},
+	}
+}
+
+// TestAllTools calls every registered tool once; adding a tool without a
+// case here fails the test.
+func TestAllTools(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	h.addMetrics(map[string]map[string][2]string{
+		"default/web-0": {"app": {"250m", "768Mi"}},
+		"node-a":        {"": {"1", "3Gi"}},
+	})
+	cases := map[string]struct {
+		args    any
+		wantErr bool
+		want    string // substring expected in the payload
+	}{
+		"echo":                  {args: map[string]any{"text": "hi"}, want: "hi"},
+		"auth-can-i":            {args: map[string]any{"verb": "delete", "resource": "deployments", "group": "apps", "namespace": "default"}, want: `"allowed":true`},
+		"api-resources":         {args: map[string]any{"group": "", "namespaced": true}, want: `"shortNames":["cm"]`},
+		"resources-explain":     {args: map[string]any{"group": "apps", "version": "v1", "kind": "Deployment", "field": "spec.template.spec.containers.resources"}, want: `"type":"ResourceRequirements"`},
+		"events-list":           {args: map[string]any{"type": "Warning", "kind": "Pod"}, want: `"reason":"BackOff"`},
+		"ns-describe":           {args: map[string]any{"name": "default"}, want: `"pods":{"hard":"10","used":"2","percent":20}`},
+		"ns-create":             {args: map[string]any{"name": "team-a", "labels": map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}}, want: `"podSecurity":{"enforce":"restricted"}`},
+		"ns-delete":             {args: map[string]any{"name": "kube-system"}, wantErr: true, want: "system namespace"},
+		"top-pods":              {args: map[string]any{"namespace": "default"}, want: `"memoryPercentOfRequest":75`},
+		"top-nodes":             {args: map[string]any{"sortBy": "cpu"}, want: `"cpuPercent":50`},
+		"pods-diagnose":         {args: map[string]any{"namespace": "default", "name": "web-0"}, want: `"cause":"NodePressure"`},
+		"pods-why-pending":      {args: map[string]any{"name": "web-0", "namespace": "default"}, want: `"scheduled":true`},
+		"cluster-health":        {args: map[string]any{}, want: `"status":"degraded"`},
+		"cluster-list-contexts": {args: map[string]any{}, wantErr: true, want: "in-cluster"},
+		"cluster-set-context":   {args: map[string]any{"context": "other"}, wantErr: true, want: "not available"},
+		"nodes-list":            {args: map[string]any{}, want: `"Pressure":["MemoryPressure"]`},
+		"nodes-cordon":          {args: map[string]any{"name": "node-a"}, want: `"dryRun":true`},
+		"nodes-uncordon":        {args: map[string]any{"name": "node-a"}, want: `"dryRun":true`},
+		"nodes-drain":           {args: map[string]any{"name": "node-a", "force": true}, want: `"status":"would-evict"`},
+		"nodes-get":             {args: map[string]any{"name": "node-a"}, want: `"requestsPercent":25`},
+		"ns-list-namespaces":    {args: map[string]any{}, want: `"Name":"default"`},
+		"pods-list-pods":        {args: map[string]any{"namespace": "default", "labelSelector": "app=web"}, want: `"Restarts":2`},
+		"pods-get":              {args: map[string]any{"namespace": "default", "name": "web-0"}, want: "nginx:1.27"},
+		"pods-logs":             {args: map[string]any{"namespace": "default", "name": "web-0"}, want: "fake logs"},
+		"logs-search":           {args: map[string]any{"namespace": "default", "labelSelector": "app=web"}, want: `"pod":"web-0","container":"app","message":"fake logs"`},
+		"portforward-start":     {args: map[string]any{"namespace": "default", "pod": "web-0", "port": 80}, wantErr: true, want: "live API server"},
+		"portforward-list":      {args: map[string]any{}, want: `"sessions":[]`},
+		"portforward-stop":      {args: map[string]any{"id": "pf-0"}, wantErr: true, want: "no port-forward session"},
+		"pods-cp-from":          {args: map[string]any{"namespace": "default", "name": "web-0", "path": "/etc/hosts"}, wantErr: true, want: "live API server"},
+		"pods-cp-to":            {args: map[string]any{"namespace": "default", "name": "web-0", "path": "/tmp/x.conf", "content": "a=1"}, want: `"dryRun":true`},
+		"workloads-scale":       {args: map[string]any{"namespace": "default", "kind": "ds", "name": "agent", "replicas": 2}, wantErr: true, want: "cannot be scaled"},
+		"rollout-restart":       {args: map[string]any{"namespace": "default", "kind": "CronJob", "name": "web"}, wantErr: true, want: "unsupported kind"},
+		"rollout-status":        {args: map[string]any{"namespace": "default", "kind": "Deployment", "name": "web"}, wantErr: true, want: "not found"},
+		"rollout-history":       {args: map[string]any{"namespace": "default", "kind": "sts", "name": "db"}, wantErr: true, want: "not found"},
+		"rollout-undo":          {args: map[string]any{"namespace": "default", "kind": "daemonset", "name": "agent"}, wantErr: true, want: "not found"},
+		"jobs-list":             {args: map[string]any{"namespace": "default"}, want: `"jobs":[]`},
+		"jobs-failed-logs":      {args: map[string]any{"namespace": "default", "name": "nightly-1"}, wantErr: true, want: "not found"},
+		"cronjobs-trigger":      {args: map[string]any{"namespace": "default", "name": "nightly"}, wantErr: true, want: "not found"},
+		"cronjobs-suspend":      {args: map[string]any{"namespace": "default", "name": "nightly"}, wantErr: true, want: "not found"},
+		"cronjobs-resume":       {args: map[string]any{"namespace": "default", "name": "nightly"}, wantErr: true, want: "not found"},
+		"cronjobs-history":      {args: map[string]any{"namespace": "default", "name": "nightly"}, wantErr: true, want: "not found"},
+		"pods-exec":             {args: map[string]any{"namespace": "default", "name": "web-0", "command": []string{"ls"}}, wantErr: true, want: "live API server"},
+		"resources-get":         {args: map[string]any{"version": "v1", "kind": "ConfigMap", "namespace": "default"}, want: `"name":"settings"`},
+		"resources-apply":       {args: map[string]any{"manifestYAML": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: default\n  labels:\n    tier: web\n"}, want: `"name":"settings"`},
+		"resources-delete":      {args: map[string]any{"version": "v1", "kind": "ConfigMap", "name": "stale", "namespace": "default", "dryRun": false}, want: "Success"},
+		"secrets-get":           {args: map[string]any{"namespace": "default", "name": "creds"}, want: "REDACTED"},
+		"secrets-set":           {args: map[string]any{"namespace": "default", "name": "creds", "data": map[string]string{"password": "x"}}, want: `"updated":true`},
+	}
+	var names []string
+	for _, tool := range h.srv.Registry().List() {
+		names = append(names, tool.Name)
+	}
+	// sorted so mutations run in a stable order (apply before delete)
+	sort.Strings(names)
+	for _, name := range names {
+		tc, ok := cases[name]
+		if !ok {
+			t.Errorf("tool %s has no test case", name)
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			out, isErr := h.call(name, tc.args)
+			if isErr != tc.wantErr {
+				t.Fatalf("isError=%v, want %v: %s", isErr, tc.wantErr, out)
+			}
+			if !strings.Contains(string(out), tc.want) {
+				t.Fatalf("payload %s does not contain %q", out, tc.want)
+			}
+		})
+	}
+}
+
+func TestPreflightDenied(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	h.denied["delete configmaps"] = true
+	out, isErr := h.call("resources-delete", map[string]any{"version": "v1", "kind": "ConfigMap", "name": "settings", "namespace": "default", "dryRun": false})
+	if !isErr || !strings.Contains(string(out), "you lack delete on configmaps in namespace default") {
+		t.Fatalf("expected RBAC denial, got %s", out)
+	}
+	out, _ = h.call("auth-can-i", map[string]any{"verb": "delete", "resource": "configmaps", "namespace": "default"})
+	if !strings.Contains(string(out), `"allowed":false`) {
+		t.Fatalf("auth-can-i: %s", out)
+	}
+}
+
+func TestApplyToolPermissions(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	h.denied["create pods/exec"] = true
+	h.denied["get secrets"] = true
+	reg := h.srv.Registry()
+
+	t.Setenv("MCP_K8S_TOOLS_RBAC", "annotate")
+	got := ApplyToolPermissions(context.Background(), reg, h.kc)
+	sort.Strings(got)
+	if strings.Join(got, ",") != "pods-cp-from,pods-cp-to,pods-exec,secrets-get" {
+		t.Fatalf("affected tools: %v", got)
+	}
+	if tool, _ := reg.Get("secrets-get"); !strings.Contains(tool.Description, "unavailable: you lack get on secrets in namespaces default") {
+		t.Fatalf("not annotated: %q", tool.Description)
+	}
+
+	t.Setenv("MCP_K8S_TOOLS_RBAC", "hide")
+	ApplyToolPermissions(context.Background(), reg, h.kc)
+	if _, ok := reg.Get("pods-exec"); ok {
+		t.Fatalf("pods-exec should be hidden")
+	}
+	if _, ok := reg.Get("pods-get"); !ok {
+		t.Fatalf("pods-get should stay listed")
+	}
+}
+
+func TestNodesDrain(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	out, isErr := h.call("nodes-drain", map[string]any{"name": "node-a", "dryRun": false})
+	if !isErr || !strings.Contains(string(out), "default/web-0: not managed by a controller") {
+		t.Fatalf("expected unmanaged pod to block the drain, got %s", out)
+	}
+	out, isErr = h.call("nodes-drain", map[string]any{"name": "node-a", "force": true, "dryRun": false, "timeoutSeconds": 5})
+	if isErr {
+		t.Fatalf("drain failed: %s", out)
+	}
+	var res struct {
+		Cordoned bool
+		Evicted  int
+		Pods     []drainPod
+	}
+	_ = json.Unmarshal(out, &res)
+	if !res.Cordoned || res.Evicted != 1 || len(res.Pods) != 2 {
+		t.Fatalf("unexpected drain result: %s", out)
+	}
+	for _, p := range res.Pods {
+		if p.Name == "agent-x1" && p.Status != "skipped" {
+			t.Fatalf("DaemonSet pod not skipped: %+v", p)
+		}
+	}
+	node, _ := h.cs.CoreV1().Nodes().Get(context.Background(), "node-a", metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Fatalf("node not cordoned")
+	}
+	if _, err := h.cs.CoreV1().Pods("default").Get(context.Background(), "web-0", metav1.GetOptions{}); err == nil {
+		t.Fatalf("web-0 not evicted")
+	}
+}
+
+func TestClusterHealth(t *testing.T) {
+	reg := mcp.NewRegistry()
+	RegisterCluster(reg, nil, slog.Default())
+	tool, _ := reg.Get("cluster-health")
+	res, err := tool.Handler(context.Background(), nil)
+	if err != nil || res.(map[string]any)["status"] != "unknown" {
+		t.Fatalf("placeholder must not report healthy: %v %v", res, err)
+	}
+
+	down := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}}}}
+	h := newHarness(t, down)
+	out, isErr := h.call("cluster-health", map[string]any{})
+	if isErr {
+		t.Fatalf("cluster-health failed: %s", out)
+	}
+	var health struct {
+		Status   string
+		Nodes    map[string]int
+		Findings []healthFinding
+	}
+	_ = json.Unmarshal(out, &health)
+	if health.Status != "unhealthy" || health.Nodes["notReady"] != 1 {
+		t.Fatalf("unexpected health: %s", out)
+	}
+	for _, f := range health.Findings {
+		if f.Check == "nodes" && f.Severity == "critical" {
+			return
+		}
+	}
+	t.Fatalf("missing critical node finding: %s", out)
+}
+
+func TestCertFindings(t *testing.T) {
+	selfSigned := func(notAfter time.Time) (tls.Certificate, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test"}, NotBefore: notAfter.Add(-48 * time.Hour), NotAfter: notAfter, IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)}}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	// an expired server certificate fails verification, yet must be reported
+	serverCert, _ := selfSigned(time.Now().Add(-time.Hour))
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	// the client certificate comes from a file, as with client-certificate
+	_, clientPEM := selfSigned(time.Now().Add(10 * 24 * time.Hour))
+	certFile := filepath.Join(t.TempDir(), "client.crt")
+	if err := os.WriteFile(certFile, clientPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var findings []healthFinding
+	add := func(severity, check, format string, args ...any) {
+		findings = append(findings, healthFinding{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+	certFindings(&rest.Config{Host: "https://" + ln.Addr().String(), TLSClientConfig: rest.TLSClientConfig{CertFile: certFile}}, add)
+	if len(findings) != 2 || findings[0].Severity != "warning" || !strings.Contains(findings[0].Message, "client certificate expires") ||
+		findings[1].Severity != "critical" || !strings.Contains(findings[1].Message, "API server") {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestResourcesExplain(t *testing.T) {
+	h := newHarness(t)
+	out, isErr := h.call("resources-explain", map[string]any{"group": "apps", "version": "v1", "kind": "Deployment", "field": "spec.template.spec.containers"})
+	if isErr {
+		t.Fatalf("explain failed: %s", out)
+	}
+	var res struct {
+		Type   string
+		Fields []explainField
+	}
+	_ = json.Unmarshal(out, &res)
+	if res.Type != "[]Container" {
+		t.Fatalf("unexpected type: %s", out)
+	}
+	found := false
+	for _, f := range res.Fields {
+		if f.Name == "name" {
+			found = f.Required && f.Type == "string" && f.Description != ""
+		}
+	}
+	if !found {
+		t.Fatalf("containers.name not described as a required string: %s", out)
+	}
+	out, isErr = h.call("resources-explain", map[string]any{"group": "apps", "version": "v1", "kind": "Deployment", "field": "spec.selector", "recursive": true})
+	if isErr || !strings.Contains(string(out), `"name":"matchExpressions","type":"[]LabelSelectorRequirement","fields":[`) {
+		t.Fatalf("recursive explain missing nested fields: %s", out)
+	}
+	out, isErr = h.call("resources-explain", map[string]any{"group": "apps", "version": "v1", "kind": "Deployment", "field": "spec.bogus"})
+	if !isErr || !strings.Contains(string(out), "available fields:") {
+		t.Fatalf("expected unknown field error, got %s", out)
+	}
+	// without a group the kind is looked up across all groups
+	out, isErr = h.call("resources-explain", map[string]any{"kind": "Deployment", "field": "spec.replicas"})
+	if isErr || !strings.Contains(string(out), `"type":"integer"`) {
+		t.Fatalf("explain without group should find apps/Deployment: %s", out)
+	}
+	out, isErr = h.call("resources-explain", map[string]any{"kind": "Event"})
+	if isErr || !strings.Contains(string(out), `"group":""`) {
+		t.Fatalf("explain without group should prefer the core Event: %s", out)
+	}
+	// a failed lookup is not cached: the next attempt asks the server again
+	h.kc.OpenAPI = k8s.NewOpenAPIClient(&flakyOpenAPI{Client: openapitest.NewEmbeddedFileClient(), failures: 1})
+	out, isErr = h.call("resources-explain", map[string]any{"group": "apps", "version": "v1", "kind": "Deployment"})
+	if isErr {
+		t.Fatalf("explain should recover from a failed OpenAPI fetch: %s", out)
+	}
+}
+
+// flakyOpenAPI fails the first few Paths calls.
+type flakyOpenAPI struct {
+	openapi.Client
+	failures int
+}
+
+func (f *flakyOpenAPI) Paths() (map[string]openapi.GroupVersion, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("connection reset")
+	}
+	return f.Client.Paths()
+}
+
+func TestEventsList(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) metav1.Time { return metav1.NewTime(now.Add(-ago)) }
+	h := newHarness(t,
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-0.a", Namespace: "default", UID: "a"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+			Type:           corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off", Count: 3,
+			FirstTimestamp: at(2 * time.Hour), LastTimestamp: at(90 * time.Minute),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-0.b", Namespace: "default", UID: "b"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+			Type:           corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off", Count: 2,
+			FirstTimestamp: at(time.Hour), LastTimestamp: at(50 * time.Minute),
+		},
+		// written through events.k8s.io: no LastTimestamp, only a series
+		&eventsv1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-0.c", Namespace: "other", UID: "c"},
+			Regarding:  corev1.ObjectReference{Kind: "Pod", Name: "db-0"},
+			Type:       corev1.EventTypeWarning, Reason: "FailedScheduling", Note: "0/3 nodes are available",
+			EventTime: metav1.NewMicroTime(now.Add(-3 * time.Hour)),
+			Series:    &eventsv1.EventSeries{Count: 7, LastObservedTime: metav1.NewMicroTime(now.Add(-time.Minute))},
+		},
+	)
+	out, isErr := h.call("events-list", map[string]any{"type": "warning"})
+	if isErr {
+		t.Fatalf("events-list failed: %s", out)
+	}
+	var res struct {
+		Events []eventRecord
+		Total  int
+	}
+	_ = json.Unmarshal(out, &res)
+	if res.Total != 2 || res.Events[0].Reason != "FailedScheduling" || res.Events[0].Count != 7 {
+		t.Fatalf("expected series event first: %s", out)
+	}
+	if e := res.Events[1]; e.Count != 5 || !e.FirstSeen.Before(e.LastSeen) {
+		t.Fatalf("expected aggregated BackOff events: %s", out)
+	}
+	out, _ = h.call("events-list", map[string]any{"namespace": "default", "since": "55m"})
+	if !strings.Contains(string(out), `"total":1`) {
+		t.Fatalf("since window not applied: %s", out)
+	}
+	// filters go to the API server as field selectors
+	h.cs.ClearActions()
+	h.call("events-list", map[string]any{"kind": "pod", "name": "web-0", "type": "warning", "reason": "BackOff"})
+	selectors := map[string]string{}
+	for _, a := range h.cs.Actions() {
+		if l, ok := a.(k8stesting.ListAction); ok && a.GetResource().Resource == "events" {
+			selectors[a.GetResource().Group] = l.GetListRestrictions().Fields.String()
+		}
+	}
+	if selectors[""] != "involvedObject.kind=Pod,involvedObject.name=web-0,reason=BackOff,type=Warning" ||
+		selectors["events.k8s.io"] != "reason=BackOff,regarding.kind=Pod,regarding.name=web-0,type=Warning" {
+		t.Fatalf("unexpected field selectors: %v", selectors)
+	}
+	h.cs.ClearActions()
+	h.call("events-list", map[string]any{"kind": "horizontalpodautoscaler"})
+	for _, a := range h.cs.Actions() {
+		if l, ok := a.(k8stesting.ListAction); ok && a.GetResource().Resource == "events" && a.GetResource().Group == "" {
+			if got := l.GetListRestrictions().Fields.String(); got != "involvedObject.kind=HorizontalPodAutoscaler" {
+				t.Fatalf("kind not resolved: %s", got)
+			}
+		}
+	}
+	// an unknown kind is not sent to the server, only filtered locally
+	h.cs.ClearActions()
+	h.call("events-list", map[string]any{"kind": "widget"})
+	for _, a := range h.cs.Actions() {
+		if l, ok := a.(k8stesting.ListAction); ok && a.GetResource().Resource == "events" && !l.GetListRestrictions().Fields.Empty() {
+			t.Fatalf("unresolved kind sent as a field selector: %s", l.GetListRestrictions().Fields)
+		}
+	}
+}
+
+func TestNamespaceDelete(t *testing.T) {
+	h := newHarness(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "locked"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "held", Namespace: "scratch", Finalizers: []string{"example.com/cleanup"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "scratch"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "empty"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "empty"}, Type: corev1.SecretTypeServiceAccountToken},
+	)
+	// objects Kubernetes creates itself are reported but do not block
+	out, isErr := h.call("ns-delete", map[string]any{"name": "empty", "dryRun": false})
+	if isErr || !strings.Contains(string(out), "still contains 1 ConfigMap, 1 Secret") || !strings.Contains(string(out), `"deleted":true`) {
+		t.Fatalf("expected namespace with only system objects to be deleted, got %s", out)
+	}
+	out, isErr = h.call("ns-delete", map[string]any{"name": "scratch", "dryRun": false})
+	if !isErr || !strings.Contains(string(out), "still contains 1 ConfigMap;") {
+		t.Fatalf("expected non-empty namespace to be refused, got %s", out)
+	}
+	if _, err := h.cs.CoreV1().Namespaces().Get(context.Background(), "scratch", metav1.GetOptions{}); err != nil {
+		t.Fatalf("namespace deleted despite refusal: %v", err)
+	}
+	out, isErr = h.call("ns-delete", map[string]any{"name": "scratch", "dryRun": false, "force": true})
+	if isErr || !strings.Contains(string(out), "ConfigMap/held (example.com/cleanup)") || !strings.Contains(string(out), `"deleted":true`) {
+		t.Fatalf("expected forced delete with finalizer warning, got %s", out)
+	}
+	if out, isErr = h.call("ns-delete", map[string]any{"name": "kube-flannel", "dryRun": false}); !isErr || !strings.Contains(string(out), "system namespace") {
+		t.Fatalf("expected kube-* namespace to be refused, got %s", out)
+	}
+	// a kind that cannot be listed might hold objects: no delete without force
+	h.cs.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("secrets is forbidden")
+	})
+	out, isErr = h.call("ns-delete", map[string]any{"name": "locked", "dryRun": false})
+	if !isErr || !strings.Contains(string(out), "could not check") {
+		t.Fatalf("expected delete to be refused when listing fails, got %s", out)
+	}
+}
+
+func TestTopWithoutMetricsServer(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	h.cs.Resources = h.cs.Resources[:len(h.cs.Resources)-1]
+	for _, tool := range []string{"top-pods", "top-nodes"} {
+		out, isErr := h.call(tool, map[string]any{})
+		if !isErr || !strings.Contains(string(out), "metrics-server does not appear to be installed") {
+			t.Fatalf("%s: expected missing metrics-server error, got %s", tool, out)
+		}
+	}
+}
+
+func TestPodsWhyPending(t *testing.T) {
+	ready := corev1.NodeStatus{
+		Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi"), corev1.ResourcePods: resource.MustParse("110")},
+	}
+	objs := append(testObjects(),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"disk": "ssd", "zone": "b"}}, Status: ready},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+			Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}},
+			}}}}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "queued", Namespace: "default", Labels: map[string]string{"app": "queued"}},
+			Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"disk": "ssd"},
+				Containers: []corev1.Container{{Name: "app", Image: "app:1", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1600m")},
+				}}},
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}},
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+					MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: corev1.DoNotSchedule,
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "queued"}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "queued.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "queued"},
+			Type:           corev1.EventTypeWarning, Reason: "FailedScheduling", Message: "0/2 nodes are available",
+		},
+	)
+	h := newHarness(t, objs...)
+	out, isErr := h.call("pods-why-pending", map[string]any{"name": "queued", "namespace": "default"})
+	if isErr {
+		t.Fatalf("pods-why-pending failed: %s", out)
+	}
+	var res struct {
+		Scheduled       bool
+		SchedulerEvents []eventRecord
+		Nodes           []nodeFit
+	}
+	_ = json.Unmarshal(out, &res)
+	if res.Scheduled || len(res.SchedulerEvents) != 1 || len(res.Nodes) != 2 {
+		t.Fatalf("unexpected result: %s", out)
+	}
+	if n := res.Nodes[0]; n.Node != "node-b" || !n.Fits {
+		t.Fatalf("node-b should fit: %s", out)
+	}
+	reasons := strings.Join(res.Nodes[1].Reasons, "\n")
+	for _, want := range []string{
+		"untolerated taint dedicated=web:NoSchedule",
+		"nodeSelector disk=ssd does not match",
+		"volume node affinity conflict (PV pv-data for claim data)",
+		"insufficient cpu: requests 1600m, free 1500m of 2 allocatable",
+		"missing topology label zone",
+	} {
+		if !strings.Contains(reasons, want) {
+			t.Errorf("node-a reasons lack %q:\n%s", want, reasons)
+		}
+	}
+}
+
+// fakeExecutor stands in for the SPDY/WebSocket exec stream.
+type fakeExecutor func(ctx context.Context, opts remotecommand.StreamOptions) error
+
+func (f fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return f(context.Background(), opts)
+}
+
+func (f fakeExecutor) StreamWithContext(ctx context.Context, opts remotecommand.StreamOptions) error {
+	return f(ctx, opts)
+}
+
+func TestPodsExec(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	orig := podExecutor
+	t.Cleanup(func() { podExecutor = orig })
+	podExecutor = func(_ *k8s.Clients, _, _ string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+		return fakeExecutor(func(ctx context.Context, s remotecommand.StreamOptions) error {
+			switch opts.Command[0] {
+			case "cat":
+				_, err := io.Copy(s.Stdout, s.Stdin)
+				return err
+			case "sleep":
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			fmt.Fprint(s.Stdout, "0123456789abcdef")
+			fmt.Fprint(s.Stderr, "boom")
+			return utilexec.CodeExitError{Err: errors.New("command terminated with exit code 3"), Code: 3}
+		}), nil
+	}
+	var res execResult
+	out, isErr := h.call("pods-exec", map[string]any{"namespace": "default", "name": "web-0", "command": []string{"false"}, "maxOutputBytes": 10})
+	if _ = json.Unmarshal(out, &res); isErr || res.ExitCode != 3 || res.Stderr != "boom" || !res.StdoutTruncated || res.Stdout != "0123456789\n...[truncated 6 bytes]" {
+		t.Fatalf("unexpected exec result: %s", out)
+	}
+	res = execResult{}
+	out, isErr = h.call("pods-exec", map[string]any{"namespace": "default", "name": "web-0", "command": []string{"cat"}, "stdin": "hello"})
+	if _ = json.Unmarshal(out, &res); isErr || res.ExitCode != 0 || res.Stdout != "hello" {
+		t.Fatalf("stdin not forwarded: %s", out)
+	}
+	res = execResult{}
+	out, isErr = h.call("pods-exec", map[string]any{"namespace": "default", "name": "web-0", "command": []string{"sleep", "60"}, "timeoutSeconds": 1})
+	if _ = json.Unmarshal(out, &res); isErr || !res.TimedOut || res.ExitCode != -1 {
+		t.Fatalf("expected timeout: %s", out)
+	}
+}
+
+func TestPodsExecPolicy(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	orig := podExecutor
+	t.Cleanup(func() { podExecutor = orig })
+	podExecutor = func(_ *k8s.Clients, _, _ string, _ *corev1.PodExecOptions) (remotecommand.Executor, error) {
+		return fakeExecutor(func(context.Context, remotecommand.StreamOptions) error { return nil }), nil
+	}
+	t.Setenv("MCP_K8S_READONLY", "true")
+	exec := func(cmd ...string) (string, bool) {
+		out, isErr := h.call("pods-exec", map[string]any{"namespace": "default", "name": "web-0", "command": cmd})
+		return string(out), isErr
+	}
+	if out, isErr := exec("ls"); !isErr || !strings.Contains(out, "read-only") {
+		t.Fatalf("exec should be blocked in read-only mode without an allowlist: %s", out)
+	}
+	t.Setenv("MCP_K8S_EXEC_ALLOW", "cat /etc/*, env, ps *")
+	t.Setenv("MCP_K8S_EXEC_DENY", "* /etc/shadow, rm, sh, bash")
+	for _, cmd := range [][]string{{"env"}, {"/bin/cat", "/etc/hosts"}, {"ps", "aux"}, {"ps"}} {
+		if out, isErr := exec(cmd...); isErr {
+			t.Fatalf("%v should be allowed: %s", cmd, out)
+		}
+	}
+	for cmd, want := range map[string]string{
+		"env FOO=1":                  "not in the exec allowlist",
+		"cat /etc/shadow":            "denied by exec policy",
+		"cat /etc/./shadow":          "denied by exec policy",
+		"cat /etc/../etc/shadow":     "denied by exec policy",
+		"cat /etc/../root/.ssh/key":  "not in the exec allowlist",
+		"/tmp/x/cat /etc/hosts":      "not in the exec allowlist",
+		"./cat /etc/hosts":           "not in the exec allowlist",
+		"cat -- /etc/shadow":         "denied by exec policy",
+		"cat /etc/hosts /etc/shadow": "denied by exec policy",
+		"rm -rf /":                   "denied by exec policy",
+		"sh -c id":                   "denied by exec policy",
+		"/bin/bash -c id":            "denied by exec policy",
+		"nc -e sh":                   "not in the exec allowlist",
+	} {
+		if out, isErr := exec(strings.Fields(cmd)...); !isErr || !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q, got %s", cmd, want, out)
+		}
+	}
+	t.Setenv("MCP_K8S_EXEC_NAMESPACES", "kube-*")
+	if out, isErr := exec("env"); !isErr || !strings.Contains(out, "not permitted in namespace default") {
+		t.Fatalf("expected namespace restriction: %s", out)
+	}
+	t.Setenv("MCP_K8S_EXEC_NAMESPACES", "")
+	t.Setenv("MCP_K8S_EXEC_IMAGES", "busybox:*")
+	if out, isErr := exec("env"); !isErr || !strings.Contains(out, "nginx:1.27") {
+		t.Fatalf("expected image restriction: %s", out)
+	}
+	t.Setenv("MCP_K8S_EXEC_IMAGES", "nginx:*")
+	if out, isErr := exec("env"); isErr {
+		t.Fatalf("nginx image should be allowed: %s", out)
+	}
+	t.Setenv("MCP_K8S_KIND_ALLOWLIST", "ConfigMap")
+	if out, isErr := exec("env"); !isErr || !strings.Contains(out, "Kind Pod is not in allowlist") {
+		t.Fatalf("expected kind allowlist to block exec: %s", out)
+	}
+}
+
+func TestPodsLogs(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	var res struct {
+		Containers []containerLogs
+		Truncated  bool
+	}
+	out, isErr := h.call("pods-logs", map[string]any{"namespace": "default", "name": "web-0", "allContainers": true, "previous": true, "include": "fake"})
+	if _ = json.Unmarshal(out, &res); isErr || len(res.Containers) != 1 || res.Containers[0].Container != "app" || res.Containers[0].Logs != "fake logs" || res.Truncated {
+		t.Fatalf("unexpected logs: %s", out)
+	}
+	var cl containerLogs
+	out, isErr = h.call("pods-logs", map[string]any{"namespace": "default", "name": "web-0", "exclude": "^fake"})
+	if _ = json.Unmarshal(out, &cl); isErr || cl.Lines != 0 || cl.Logs != "" {
+		t.Fatalf("exclude not applied: %s", out)
+	}
+	if out, isErr = h.call("pods-logs", map[string]any{"name": "web-0", "sinceSeconds": 60, "sinceTime": "2024-01-01T00:00:00Z"}); !isErr {
+		t.Fatalf("expected sinceSeconds/sinceTime conflict: %s", out)
+	}
+	if out, isErr = h.call("pods-logs", map[string]any{"name": "web-0", "include": "("}); !isErr || !strings.Contains(string(out), "invalid include pattern") {
+		t.Fatalf("expected invalid pattern error: %s", out)
+	}
+
+	f, _ := newLogFilter("ERROR", "healthz", 1)
+	lines := []string{"a", "ERROR one", "b", "GET /healthz", "c", "d", "ERROR two", "e"}
+	if got := strings.Join(f.apply(lines), "|"); got != "a|ERROR one|b|--|d|ERROR two|e" {
+		t.Fatalf("unexpected filter output: %s", got)
+	}
+}
+
+func TestLogsSearch(t *testing.T) {
+	objs := append(testObjects(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	})
+	h := newHarness(t, objs...)
+	var res struct {
+		Selector string
+		Sources  int
+		Lines    []logLine
+	}
+	out, isErr := h.call("logs-search", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "include": "^fake"})
+	if _ = json.Unmarshal(out, &res); isErr || res.Selector != "app=web" || res.Sources != 1 || len(res.Lines) != 1 {
+		t.Fatalf("unexpected search result: %s", out)
+	}
+	if out, isErr = h.call("logs-search", map[string]any{"namespace": "default"}); !isErr || !strings.Contains(string(out), "required") {
+		t.Fatalf("expected missing selector error: %s", out)
+	}
+	if out, isErr = h.call("logs-search", map[string]any{"namespace": "default", "kind": "CronJob", "name": "x"}); !isErr || !strings.Contains(string(out), "unsupported kind") {
+		t.Fatalf("expected unsupported kind error: %s", out)
+	}
+
+	q, _ := newLogQuery(logParams{Exclude: "healthz"})
+	q.until = time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+	q.scan = 3
+	raw, cut := q.trim("2024-05-01T12:00:00Z booting\n" +
+		"2024-05-01T12:00:01.5Z GET /healthz\n" +
+		"2024-05-01T12:00:02Z started\n" +
+		"  continued\n" +
+		"2024-05-01T12:00:09Z late\n" +
+		"2024-05-01T12:00:10Z later\n")
+	// the tail is taken below until, not from the end of the log
+	if len(raw) != 3 || len(cut) != 1 || cut[0] != "tailLines" {
+		t.Fatalf("unexpected trim: %q %v", raw, cut)
+	}
+	got := q.searchLines(raw, logSource{"web-0", "app"}, nil)
+	if len(got) != 2 || got[0].Message != "started" || got[1].Message != "  continued" || !got[1].ts.Equal(got[0].ts) {
+		t.Fatalf("unexpected lines: %+v", got)
+	}
+	limit := int64(32)
+	q = &logQuery{opts: corev1.PodLogOptions{LimitBytes: &limit}}
+	if raw, cut = q.trim("2024-05-01T12:00:00Z booting\n2024-05-01T12:00"); len(raw) != 1 || len(cut) != 1 || cut[0] != "limitBytes" {
+		t.Fatalf("partial line not dropped: %q %v", raw, cut)
+	}
+}
+
+func TestPodsLogsFollow(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	var res followResult
+	out, isErr := h.call("pods-logs", map[string]any{"namespace": "default", "name": "web-0", "follow": true, "followSeconds": 5})
+	if _ = json.Unmarshal(out, &res); isErr || res.StoppedBy != "ended" || res.Logs != "fake logs" {
+		t.Fatalf("unexpected follow result: %s", out)
+	}
+	if out, isErr = h.call("pods-logs", map[string]any{"name": "web-0", "follow": true, "allContainers": true}); !isErr {
+		t.Fatalf("follow with allContainers should fail: %s", out)
+	}
+
+	// lines arrive as progress notifications while the call runs
+	req, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{
+		"name":      "pods-logs",
+		"arguments": map[string]any{"namespace": "default", "name": "web-0", "follow": true, "stopPattern": "^fake"},
+		"_meta":     map[string]any{"progressToken": "tok"},
+	}})
+	var buf bytes.Buffer
+	if err := h.srv.Run(context.Background(), bytes.NewReader(append(req, '\n')), &buf); err != nil {
+		t.Fatal(err)
+	}
+	msgs := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(msgs) != 2 || !strings.Contains(msgs[0], `"method":"notifications/progress"`) || !strings.Contains(msgs[0], `"message":"fake logs"`) {
+		t.Fatalf("expected a progress notification per line, got %s", buf.String())
+	}
+	if !strings.Contains(msgs[1], `"stoppedBy":"pattern"`) {
+		t.Fatalf("expected stop on pattern: %s", msgs[1])
+	}
+
+	// without a progress token they arrive as log messages
+	req, _ = json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": map[string]any{
+		"name":      "pods-logs",
+		"arguments": map[string]any{"namespace": "default", "name": "web-0", "follow": true, "stopPattern": "^fake"},
+	}})
+	buf.Reset()
+	if err := h.srv.Run(context.Background(), bytes.NewReader(append(req, '\n')), &buf); err != nil {
+		t.Fatal(err)
+	}
+	msgs = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(msgs) != 2 || !strings.Contains(msgs[0], `"method":"notifications/message"`) || !strings.Contains(msgs[0], `"data":"fake logs"`) {
+		t.Fatalf("expected a log message per line, got %s", buf.String())
+	}
+}
+
+func TestJSONLogs(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	var cl containerLogs
+	out, isErr := h.call("pods-logs", map[string]any{"namespace": "default", "name": "web-0", "json": true})
+	if _ = json.Unmarshal(out, &cl); isErr || cl.Summary == nil || cl.Summary.Unparsed != 1 || cl.Lines != 0 {
+		t.Fatalf("plain text lines should be counted as unparsed: %s", out)
+	}
+	if out, isErr = h.call("pods-logs", map[string]any{"name": "web-0", "where": []string{"level"}}); !isErr || !strings.Contains(string(out), "invalid where") {
+		t.Fatalf("expected invalid where error: %s", out)
+	}
+
+	q, err := newLogQuery(logParams{Where: []string{"level!=debug", "req.path~^/api"}, Fields: []string{"msg", "req.id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		`{"level":"info","time":"t1","msg":"listening","req":{"path":"/api/a","id":"1"}}`,
+		`{"level":"debug","time":"t2","msg":"noise","req":{"path":"/api/b","id":"2"}}`,
+		`panic: runtime error`,
+		`{"level":50,"time":"t3","msg":"db timeout","req":{"path":"/api/c","id":"3"}}`,
+		`{"level":"ERROR","time":"t4","msg":"db timeout","req":{"path":"/api/d","id":"4"}}`,
+		`{"level":"error","time":"t5","msg":"bad input","req":{"path":"/healthz","id":"5"}}`,
+	}
+	q.fetchJSON(&cl, lines)
+	s := cl.Summary
+	if s.Parsed != 5 || s.Unparsed != 1 || s.Matched != 3 || s.Levels["error"] != 2 || s.Levels["info"] != 1 || s.First != "t1" || s.Last != "t4" {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if len(s.TopErrors) != 1 || s.TopErrors[0] != (errorMessage{Message: "db timeout", Count: 2, First: "t3", Last: "t4"}) {
+		t.Fatalf("unexpected top errors: %+v", s.TopErrors)
+	}
+	if len(cl.Records) != 3 || len(cl.Records[0]) != 2 || cl.Records[2]["req.id"] != "4" {
+		t.Fatalf("unexpected projection: %+v", cl.Records)
+	}
+}
+
+type fakeForwarder struct {
+	stop  <-chan struct{}
+	ready chan struct{}
+}
+
+func (f fakeForwarder) ForwardPorts() error {
+	close(f.ready)
+	<-f.stop
+	return nil
+}
+
+func (f fakeForwarder) GetPorts() ([]portforward.ForwardedPort, error) {
+	return []portforward.ForwardedPort{{Local: 41234, Remote: 8080}}, nil
+}
+
+func TestPortForward(t *testing.T) {
+	h := newHarness(t, append(testObjects(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx:1.27"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}, Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}}},
+	})...)
+	orig := newForwarder
+	t.Cleanup(func() { newForwarder = orig })
+	var target string
+	newForwarder = func(_ *k8s.Clients, ns, pod, _ string, port int, stop <-chan struct{}, ready chan struct{}) (forwarder, error) {
+		target = fmt.Sprintf("%s/%s:%d", ns, pod, port)
+		return fakeForwarder{stop, ready}, nil
+	}
+	var s forwardSession
+	out, isErr := h.call("portforward-start", map[string]any{"namespace": "default", "pod": "web-0", "port": 8080})
+	if _ = json.Unmarshal(out, &s); isErr || s.LocalAddress != "127.0.0.1:41234" || s.Status != "active" || target != "default/web-0:8080" {
+		t.Fatalf("unexpected session: %s", out)
+	}
+	t.Cleanup(func() { forwards.remove(s.ID) })
+	if out, _ = h.call("portforward-list", map[string]any{}); !strings.Contains(string(out), `"id":"`+s.ID+`"`) {
+		t.Fatalf("session not listed: %s", out)
+	}
+	if out, isErr = h.call("portforward-stop", map[string]any{"id": s.ID}); isErr {
+		t.Fatalf("stop failed: %s", out)
+	}
+	if out, _ = h.call("portforward-list", map[string]any{}); strings.Contains(string(out), s.ID) {
+		t.Fatalf("stopped session still listed: %s", out)
+	}
+	if out, isErr = h.call("portforward-start", map[string]any{"namespace": "default", "pod": "web-0", "port": 8080, "address": "0.0.0.0"}); !isErr || !strings.Contains(string(out), "not a loopback address") {
+		t.Fatalf("expected non-loopback address to be refused: %s", out)
+	}
+	t.Setenv("MCP_K8S_PORTFORWARD_ALLOW_REMOTE", "true")
+	s = forwardSession{}
+	out, isErr = h.call("portforward-start", map[string]any{"namespace": "default", "pod": "web-0", "port": 8080, "address": "0.0.0.0"})
+	if _ = json.Unmarshal(out, &s); isErr || s.LocalAddress != "0.0.0.0:41234" {
+		t.Fatalf("expected opt-in to allow other interfaces: %s", out)
+	}
+	forwards.remove(s.ID)
+	// web-0 is not Ready, so the service resolves to web-1 and its target port
+	s = forwardSession{}
+	out, isErr = h.call("portforward-start", map[string]any{"namespace": "default", "service": "web", "port": 80})
+	if _ = json.Unmarshal(out, &s); isErr || target != "default/web-1:8080" || s.Service != "web" {
+		t.Fatalf("unexpected service session: %s", out)
+	}
+	forwards.remove(s.ID)
+	t.Setenv("MCP_K8S_READONLY", "true")
+	if out, isErr = h.call("portforward-start", map[string]any{"namespace": "default", "pod": "web-0", "port": 8080}); !isErr || !strings.Contains(string(out), "read-only") {
+		t.Fatalf("expected read-only block: %s", out)
+	}
+}
+
+func TestPodsCopy(t *testing.T) {
+	h := newHarness(t, testObjects()...)
+	orig := podExecutor
+	t.Cleanup(func() { podExecutor = orig })
+	var uploaded map[string]string
+	podExecutor = func(_ *k8s.Clients, _, _ string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+		return fakeExecutor(func(_ context.Context, s remotecommand.StreamOptions) error {
+			switch strings.Join(opts.Command, " ") {
+			case "tar cf - -C /etc app":
+				tw := tar.NewWriter(s.Stdout)
+				for name, body := range map[string]string{"app/app.conf": "port=80\n", "app/blob.bin": "\x00\x01\x02"} {
+					_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o640, Size: int64(len(body)), Typeflag: tar.TypeReg})
+					_, _ = tw.Write([]byte(body))
+				}
+				_ = tw.WriteHeader(&tar.Header{Name: "app/current", Linkname: "app.conf", Typeflag: tar.TypeSymlink})
+				return tw.Close()
+			case "tar -xmf - -C /tmp":
+				uploaded = map[string]string{}
+				tr := tar.NewReader(s.Stdin)
+				for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+					b, _ := io.ReadAll(tr)
+					uploaded[hdr.Name] = fmt.Sprintf("%o:%s", hdr.Mode, b)
+				}
+				return nil
+			}
+			fmt.Fprint(s.Stderr, "tar: missing: No such file or directory")
+			return utilexec.CodeExitError{Err: errors.New("command terminated with exit code 2"), Code: 2}
+		}), nil
+	}
+	var res struct{ Files []copiedFile }
+	out, isErr := h.call("pods-cp-from", map[string]any{"namespace": "default", "name": "web-0", "path": "/etc/app"})
+	if _ = json.Unmarshal(out, &res); isErr || len(res.Files) != 3 {
+		t.Fatalf("unexpected copy result: %s", out)
+	}
+	got := map[string]copiedFile{}
+	for _, f := range res.Files {
+		got[f.Path] = f
+	}
+	if f := got["/etc/app/app.conf"]; f.Encoding != "text" || f.Content != "port=80\n" || f.Mode != "0640" {
+		t.Fatalf("unexpected text file: %+v", f)
+	}
+	if f := got["/etc/app/blob.bin"]; f.Encoding != "base64" || f.Content != "AAEC" {
+		t.Fatalf("unexpected binary file: %+v", f)
+	}
+	if f := got["/etc/app/current"]; f.Type != "symlink" || f.Target != "app.conf" {
+		t.Fatalf("unexpected symlink: %+v", f)
+	}
+
+	t.Setenv("MCP_K8S_CP_DIR", t.TempDir())
+	res.Files = nil
+	out, isErr = h.call("pods-cp-from", map[string]any{"namespace": "default", "name": "web-0", "path": "/etc/app/", "save": true})
+	if _ = json.Unmarshal(out, &res); isErr || len(res.Files) != 3 {
+		t.Fatalf("unexpected save result: %s", out)
+	}
+	for _, f := range res.Files {
+		if f.Path != "/etc/app/app.conf" {
+			continue
+		}
+		u, _ := url.Parse(f.URI)
+		if b, err := os.ReadFile(u.Path); err != nil || string(b) != "port=80\n" || f.Content != "" {
+			t.Fatalf("saved file %s: %q, %v", f.URI, b, err)
+		}
+	}
+	shared := t.TempDir()
+	if err := os.Chmod(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCP_K8S_CP_DIR", shared)
+	if out, isErr = h.call("pods-cp-from", map[string]any{"namespace": "default", "name": "web-0", "path": "/etc/app/", "save": true}); !isErr || !strings.Contains(string(out), "writable by other users") {
+		t.Fatalf("expected a shared save directory to be refused: %s", out)
+	}
+	if out, isErr = h.call("pods-cp-from", map[string]any{"namespace": "default", "name": "web-0", "path": "/missing"}); !isErr || !strings.Contains(string(out), "No such file") {
+		t.Fatalf("expected tar error: %s", out)
+	}
+
+	out, isErr = h.call("pods-cp-to", map[string]any{"namespace": "default", "name": "web-0", "path": "/tmp/run.sh", "content": "IyEvYmluL3No", "encoding": "base64", "mode": "0755"})
+	if isErr || uploaded != nil || !strings.Contains(string(out), `"container":"app"`) {
+		t.Fatalf("dry run should not upload: %s", out)
+	}
+	out, isErr = h.call("pods-cp-to", map[string]any{"namespace": "default", "name": "web-0", "path": "/tmp/run.sh", "content": "IyEvYmluL3No", "encoding": "base64", "mode": "0755", "dryRun": false})
+	if isErr || uploaded["run.sh"] != "755:#!/bin/sh" {
+		t.Fatalf("unexpected upload %v: %s", uploaded, out)
+	}
+	t.Setenv("MCP_K8S_READONLY", "true")
+	if out, isErr = h.call("pods-cp-to", map[string]any{"namespace": "default", "name": "web-0", "path": "/tmp/x", "content": "x"}); !isErr || !strings.Contains(string(out), "read-only") {
+		t.Fatalf("expected read-only block: %s", out)
+	}
+}
+
+func TestRollout(t *testing.T) {
+	replicas := int32(3)
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid", Generation: 2, Annotations: map[string]string{revisionAnnotation: "2"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx:1.27"}}}},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+	}
+	replicaSet := func(rev, image string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-" + rev, Namespace: "default", Labels: map[string]string{"app": "web"},
+				Annotations:     map[string]string{revisionAnnotation: rev, changeCauseAnnotation: "set image " + image},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(web, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: "h" + rev}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+			}},
+		}
+	}
+	db := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "db-uid"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "pg", Image: "postgres:16"}}}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "db-2"},
+	}
+	revision := func(rev int64, image string) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("db-%d", rev), Namespace: "default", Labels: map[string]string{"app": "db"},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(db, appsv1.SchemeGroupVersion.WithKind("StatefulSet"))},
+			},
+			Revision: rev,
+			Data:     runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"pg","image":"` + image + `"}]}}}}`)},
+		}
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-hpa", Namespace: "default"},
+		Spec:       autoscalingv2.HorizontalPodAutoscalerSpec{ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"}},
+	}
+	h := newHarness(t, append(testObjects(), web, replicaSet("1", "nginx:1.26"), replicaSet("2", "nginx:1.27"), db, revision(1, "postgres:15"), revision(2, "postgres:16"), hpa)...)
+	deployments := h.cs.AppsV1().Deployments("default")
+
+	var hist struct{ Revisions []revisionInfo }
+	out, isErr := h.call("rollout-history", map[string]any{"namespace": "default", "kind": "deploy", "name": "web"})
+	if _ = json.Unmarshal(out, &hist); isErr || len(hist.Revisions) != 2 || hist.Revisions[0].Current || !hist.Revisions[1].Current || hist.Revisions[0].Images[0] != "nginx:1.26" {
+		t.Fatalf("unexpected history: %s", out)
+	}
+
+	out, isErr = h.call("rollout-status", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web"})
+	if isErr || !strings.Contains(string(out), `"done":true`) {
+		t.Fatalf("expected a finished rollout: %s", out)
+	}
+
+	// undo without a revision goes back to the previous one
+	out, isErr = h.call("rollout-undo", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "dryRun": false})
+	if isErr || !strings.Contains(string(out), `"toRevision":1`) {
+		t.Fatalf("unexpected undo result: %s", out)
+	}
+	d, _ := deployments.Get(context.Background(), "web", metav1.GetOptions{})
+	if img := d.Spec.Template.Spec.Containers[0].Image; img != "nginx:1.26" || d.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] != "" {
+		t.Fatalf("template not restored: %s %v", img, d.Spec.Template.Labels)
+	}
+	if out, _ = h.call("rollout-undo", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "toRevision": 2}); !strings.Contains(string(out), `"skipped"`) {
+		t.Fatalf("undo to the current revision should be skipped: %s", out)
+	}
+	if out, isErr = h.call("rollout-undo", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "toRevision": 9}); !isErr || !strings.Contains(string(out), "available: 1, 2") {
+		t.Fatalf("expected unknown revision error: %s", out)
+	}
+
+	out, isErr = h.call("rollout-undo", map[string]any{"namespace": "default", "kind": "StatefulSet", "name": "db", "dryRun": false})
+	s, _ := h.cs.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	if isErr || s.Spec.Template.Spec.Containers[0].Image != "postgres:15" {
+		t.Fatalf("statefulset not rolled back: %s %+v", out, s.Spec.Template.Spec.Containers)
+	}
+
+	out, isErr = h.call("workloads-scale", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "replicas": 5, "dryRun": false})
+	if isErr || !strings.Contains(string(out), `"from":3`) || !strings.Contains(string(out), "web-hpa") {
+		t.Fatalf("unexpected scale result: %s", out)
+	}
+
+	out, isErr = h.call("rollout-restart", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web", "dryRun": false})
+	d, _ = deployments.Get(context.Background(), "web", metav1.GetOptions{})
+	if isErr || d.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		t.Fatalf("restart annotation not set: %s", out)
+	}
+
+	// a stuck rollout is reported as failed, and a paused one cannot restart
+	d.Spec.Paused = true
+	d.Status.UpdatedReplicas = 1
+	d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}}
+	if _, err := deployments.Update(context.Background(), d, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ = h.call("rollout-status", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web"}); !strings.Contains(string(out), `"failed":true`) {
+		t.Fatalf("expected a failed rollout: %s", out)
+	}
+	if out, isErr = h.call("rollout-restart", map[string]any{"namespace": "default", "kind": "Deployment", "name": "web"}); !isErr || !strings.Contains(string(out), "paused") {
+		t.Fatalf("expected paused error: %s", out)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	cron := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: "cron-uid"},
+		Spec: batchv1.CronJobSpec{Schedule: "0 2 * * *", JobTemplate: batchv1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "data"}},
+			Spec:       batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "run", Image: "etl:2"}}}}},
+		}},
+	}
+	job := func(name string, created metav1.Time, cond batchv1.JobConditionType, reason string) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", CreationTimestamp: created,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cron, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+			},
+			Spec: batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": name}}},
+			Status: batchv1.JobStatus{
+				StartTime:  &created,
+				Conditions: []batchv1.JobCondition{{Type: cond, Status: corev1.ConditionTrue, Reason: reason}},
+			},
+		}
+	}
+	failedPod := func(name string, code int32, reason string, finished time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"job-name": "nightly-1"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "run", Image: "etl:2"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "run",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: code, Reason: reason, FinishedAt: metav1.NewTime(finished)}},
+			}}},
+		}
+	}
+	h := newHarness(t, append(testObjects(), cron,
+		job("nightly-1", start, batchv1.JobFailed, "BackoffLimitExceeded"),
+		job("nightly-2", metav1.NewTime(start.Add(time.Minute)), batchv1.JobComplete, ""),
+		failedPod("nightly-1-a", 1, "Error", start.Add(time.Second)),
+		failedPod("nightly-1-b", 137, "OOMKilled", start.Add(2*time.Second)),
+	)...)
+
+	var list struct{ Jobs []jobSummary }
+	out, isErr := h.call("jobs-list", map[string]any{"namespace": "default", "cronJob": "nightly", "status": "failed"})
+	if _ = json.Unmarshal(out, &list); isErr || len(list.Jobs) != 1 || list.Jobs[0].Reason != "BackoffLimitExceeded" || list.Jobs[0].Completions != "0/1" {
+		t.Fatalf("unexpected job list: %s", out)
+	}
+
+	// the latest failure wins
+	out, isErr = h.call("jobs-failed-logs", map[string]any{"namespace": "default", "name": "nightly-1"})
+	if isErr || !strings.Contains(string(out), `"pod":"nightly-1-b"`) || !strings.Contains(string(out), `"exitCode":137`) || !strings.Contains(string(out), "fake logs") {
+		t.Fatalf("unexpected failed logs: %s", out)
+	}
+	if out, isErr = h.call("jobs-failed-logs", map[string]any{"namespace": "default", "name": "nightly-2"}); !isErr || !strings.Contains(string(out), "no failed pods") {
+		t.Fatalf("expected no failed pods error: %s", out)
+	}
+
+	var hist struct {
+		Outcomes map[string]int
+		Jobs     []jobSummary
+	}
+	out, isErr = h.call("cronjobs-history", map[string]any{"namespace": "default", "name": "nightly"})
+	if _ = json.Unmarshal(out, &hist); isErr || len(hist.Jobs) != 2 || hist.Jobs[0].Name != "nightly-2" || hist.Outcomes["Failed"] != 1 {
+		t.Fatalf("unexpected history: %s", out)
+	}
+
+	out, isErr = h.call("cronjobs-trigger", map[string]any{"namespace": "default", "name": "nightly", "jobName": "nightly-now", "dryRun": false})
+	created, err := h.cs.BatchV1().Jobs("default").Get(context.Background(), "nightly-now", metav1.GetOptions{})
+	if isErr || err != nil || !metav1.IsControlledBy(created, cron) || created.Annotations["cronjob.kubernetes.io/instantiate"] != "manual" || created.Labels["team"] != "data" {
+		t.Fatalf("unexpected triggered job: %s %v", out, err)
+	}
+	if out, _ = h.call("cronjobs-trigger", map[string]any{"namespace": "default", "name": "nightly"}); !strings.Contains(string(out), `"job":"nightly-manual-`) {
+		t.Fatalf("expected a generated job name: %s", out)
+	}
+
+	if out, _ = h.call("cronjobs-suspend", map[string]any{"namespace": "default", "name": "nightly", "dryRun": false}); !strings.Contains(string(out), `"suspend":true`) {
+		t.Fatalf("expected suspended cronjob: %s", out)
+	}
+	if out, _ = h.call("cronjobs-resume", map[string]any{"namespace": "default", "name": "nightly", "dryRun": false}); !strings.Contains(string(out), `"suspend":false`) {
+		t.Fatalf("expected resumed cronjob: %s", out)
+	}
+	t.Setenv("MCP_K8S_READONLY", "true")
+	if out, isErr = h.call("cronjobs-trigger", map[string]any{"namespace": "default", "name": "nightly"}); !isErr || !strings.Contains(string(out), "read-only") {
+		t.Fatalf("expected read-only refusal: %s", out)
+	}
+}
+
+func TestPodsDiagnose(t *testing.T) {
+	labels := map[string]string{"app": "api"}
+	crashing := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default", Labels: labels},
+		Spec: corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{
+			Name: "api", Image: "api:3",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}},
+			Env: []corev1.EnvVar{
+				{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "api-creds"}, Key: "token"}}},
+				{Name: "MODE", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "mode"}}},
+				{Name: "A", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "a"}}},
+			},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "api", RestartCount: 5,
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s restarting failed container"}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+		}}},
+	}
+	healthy := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "default", Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{Name: "api", Image: "api:3"}}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "api", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+	probe := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "api-0.1", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "api-0", Namespace: "default"},
+		Type:           corev1.EventTypeWarning, Reason: "Unhealthy", Message: "Liveness probe failed: HTTP probe failed with statuscode: 500", Count: 12,
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+	}
+	h := newHarness(t, append(testObjects(), crashing, healthy, probe, deploy)...)
+
+	var res struct {
+		Pod    string
+		Causes []podCause
+		Logs   struct {
+			Container string
+			Previous  bool
+		}
+		Workload struct{ Pods []map[string]any }
+	}
+	out, isErr := h.call("pods-diagnose", map[string]any{"namespace": "default", "kind": "deploy", "name": "api"})
+	if _ = json.Unmarshal(out, &res); isErr || res.Pod != "api-0" || len(res.Workload.Pods) != 2 {
+		t.Fatalf("expected the crashing pod of the workload: %s", out)
+	}
+	if top := res.Causes[0]; top.Cause != "OOMKilled" || !strings.Contains(strings.Join(top.Evidence, "\n"), "memory limit is 256Mi") {
+		t.Fatalf("expected OOMKilled to rank first: %+v", res.Causes)
+	}
+	found := map[string]string{}
+	for _, c := range res.Causes {
+		found[c.Cause] = strings.Join(c.Evidence, "\n")
+	}
+	for cause, evidence := range map[string]string{
+		"MissingSecret":        "Secret api-creds not found (used by container api env TOKEN)",
+		"MissingConfigMapKey":  `ConfigMap settings has no key "mode"`,
+		"LivenessProbeFailing": "statuscode: 500 (x12)",
+		"NodePressure":         "MemoryPressure",
+	} {
+		if !strings.Contains(found[cause], evidence) {
+			t.Errorf("cause %s: evidence %q does not contain %q", cause, found[cause], evidence)
+		}
+	}
+	if res.Logs.Container != "api" || !res.Logs.Previous {
+		t.Fatalf("expected the previous log of the crashed container: %s", out)
+	}
+
+	if out, isErr = h.call("pods-diagnose", map[string]any{"namespace": "default", "name": "api-1"}); isErr || !strings.Contains(string(out), `"cause":"NodePressure"`) || strings.Contains(string(out), "OOMKilled") {
+		t.Fatalf("unexpected diagnosis of a healthy pod: %s", out)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/remotecommand"
 
 	"github.com/example/mcp-k8s-server-go/internal/authz"
 	"github.com/example/mcp-k8s-server-go/pkg/k8s"
@@ -23,8 +25,8 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 		}
 		reg.Register(mcp.Tool{Name: "pods-list-pods", Description: "List pods with optional selectors", DirectResult: true, Handler: notReady})
 		reg.Register(mcp.Tool{Name: "pods-get", Description: "Get a pod summary including containers and events", DirectResult: true, Handler: notReady})
-		reg.Register(mcp.Tool{Name: "pods-logs", Description: "Get pod logs (tail by default)", DirectResult: true, Handler: notReady})
-		reg.Register(mcp.Tool{Name: "pods-exec", Description: "Execute a command in a pod", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "pods-logs", Description: "Get pod logs (last 1000 lines by default) with previous/all containers, byte limits and regex filtering", DirectResult: true, Handler: notReady})
+		reg.Register(mcp.Tool{Name: "pods-exec", Description: "Execute a command in a pod and return stdout, stderr and the exit code", DirectResult: true, Handler: notReady})
 		return
 	}
 	// pods-list-pods
@@ -32,8 +34,9 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 		Name:         "pods-list-pods",
 		Description:  "List pods with optional selectors",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("pods-list-pods", 10, 5)
+			k := k.Current()
 			var p struct {
 				Namespace     string `json:"namespace"`
 				LabelSelector string `json:"labelSelector"`
@@ -66,7 +69,7 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 				rows = rows[:*p.Limit]
 			}
 			return map[string]any{"pods": rows}, nil
-		},
+		}),
 	})
 
 	// pods-get
@@ -74,8 +77,9 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 		Name:         "pods-get",
 		Description:  "Get a pod summary including containers and events",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("pods-get", 10, 5)
+			k := k.Current()
 			var p struct{ Namespace, Name string }
 			if err := json.Unmarshal(params, &p); err != nil {
 				return nil, err
@@ -100,8 +104,10 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 			}
 			var events []evRow
 			if ev != nil {
-				for _, e := range ev.Items {
-					events = append(events, evRow{Type: e.Type, Reason: e.Reason, Message: e.Message, Age: e.LastTimestamp})
+				sort.SliceStable(ev.Items, func(i, j int) bool { return eventLastSeen(&ev.Items[i]).Before(eventLastSeen(&ev.Items[j])) })
+				for i := range ev.Items {
+					e := &ev.Items[i]
+					events = append(events, evRow{Type: e.Type, Reason: e.Reason, Message: e.Message, Age: metav1.NewTime(eventLastSeen(e))})
 				}
 				if len(events) > 10 {
 					events = events[len(events)-10:]
@@ -115,7 +121,7 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 				"events":         events,
 			}
 			return out, nil
-		},
+		}),
 	})
 
 	// pods-logs
@@ -123,59 +129,139 @@ func RegisterWorkloads(reg *mcp.Registry, k *k8s.Clients) {
 		Name:         "pods-logs",
 		Description:  "Get pod logs (tail by default)",
 		DirectResult: true,
-		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
+		Handler: readOnly(func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("pods-logs", 10, 5)
+			k := k.Current()
 			var p struct {
 				Namespace, Name, Container string
-				TailLines                  *int64
-				SinceSeconds               *int64
-				Timestamps                 *bool
+				AllContainers              bool
+				logParams
+				// follow mode
+				Follow        bool
+				FollowSeconds int
+				MaxLines      int
+				StopPattern   string
 			}
 			if err := json.Unmarshal(params, &p); err != nil {
 				return nil, err
 			}
-			text, err := k.PodLogs(ctx, p.Namespace, p.Name, p.Container, p.TailLines, p.SinceSeconds, p.Timestamps)
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
+			}
+			q, err := newLogQuery(p.logParams)
 			if err != nil {
 				return nil, err
 			}
-			lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
-			if len(lines) > 1000 {
-				lines = lines[len(lines)-1000:]
+			if p.Follow {
+				if p.AllContainers || p.Previous {
+					return nil, errors.New("follow works on the running instance of one container; drop allContainers and previous")
+				}
+				var stop *regexp.Regexp
+				if p.StopPattern != "" {
+					if stop, err = regexp.Compile(p.StopPattern); err != nil {
+						return nil, fmt.Errorf("invalid stopPattern: %w", err)
+					}
+				}
+				d := defaultFollowDuration
+				if p.FollowSeconds > 0 {
+					d = min(time.Duration(p.FollowSeconds)*time.Second, maxFollowDuration)
+				}
+				maxLines := maxLogLines
+				if p.MaxLines > 0 {
+					maxLines = min(p.MaxLines, maxLogLines)
+				}
+				return q.follow(ctx, k, p.Namespace, p.Name, p.Container, d, maxLines, stop)
+			}
+			if !p.AllContainers {
+				cl, err := q.fetch(ctx, k, p.Namespace, p.Name, p.Container)
+				if err != nil {
+					return nil, err
+				}
+				return cl, nil
 			}
-			return strings.Join(lines, "\n"), nil
-		},
+			pod, err := k.Clientset.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			// init containers first, in the order they ran
+			var out []containerLogs
+			truncated := false
+			for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				cl, err := q.fetch(ctx, k, p.Namespace, p.Name, c.Name)
+				if err != nil {
+					cl.Error = err.Error()
+				}
+				cl.Init = len(out) < len(pod.Spec.InitContainers)
+				truncated = truncated || cl.Truncated
+				out = append(out, cl)
+			}
+			return map[string]any{"namespace": p.Namespace, "pod": p.Name, "containers": out, "truncated": truncated}, nil
+		}),
 	})
 
-	// pods-exec – approximate: 0 if no error, 1 otherwise
+	// pods-exec
 	reg.Register(mcp.Tool{
 		Name:         "pods-exec",
-		Description:  "Execute a command in a pod",
+		Description:  "Execute a command in a pod and return stdout, stderr and the exit code",
 		DirectResult: true,
 		Handler: func(ctx context.Context, params json.RawMessage) (any, error) {
 			_ = authz.RateLimit("pods-exec", 5, 2)
+			k := k.Current()
 			var p struct {
 				Namespace, Name, Container string
 				Command                    []string `json:"command"`
-				DryRun                     *bool    `json:"dryRun"`
+				Stdin                      *string  `json:"stdin"`
+				TimeoutSeconds             int      `json:"timeoutSeconds"`
+				MaxOutputBytes             int      `json:"maxOutputBytes"`
 			}
 			if err := json.Unmarshal(params, &p); err != nil {
 				return nil, err
 			}
-			if err := authz.EnforceMutating("pods-exec", p.Namespace, "Pod"); err != nil {
-				return nil, err
+			if p.Namespace == "" {
+				p.Namespace = k.DefaultNamespace
 			}
-			req := k.Clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(p.Namespace).Name(p.Name).SubResource("exec").Param("container", p.Container)
-			req.VersionedParams(&corev1.PodExecOptions{Container: p.Container, Command: p.Command, Stdin: false, Stdout: false, Stderr: false, TTY: false}, scheme.ParameterCodec)
-			executor, err := remotecommand.NewSPDYExecutor(k.RestConfig, "POST", req.URL())
-			if err != nil {
+			if len(p.Command) == 0 {
+				return nil, errors.New("command is required")
+			}
+			r := execRequest{Namespace: p.Namespace, Pod: p.Name, Container: p.Container, Command: p.Command, MaxOutput: min(p.MaxOutputBytes, maxExecOutput)}
+			if err := enforceExec(ctx, k, "pods-exec", &r); err != nil {
 				return nil, err
 			}
-			err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{})
-			exit := 0
-			if err != nil {
-				exit = 1
+			if p.Stdin != nil {
+				r.Stdin = strings.NewReader(*p.Stdin)
 			}
-			return map[string]int{"exitCode": exit}, nil
+			if p.TimeoutSeconds > 0 {
+				r.Timeout = min(time.Duration(p.TimeoutSeconds)*time.Second, maxExecTimeout)
+			}
+			return execInPod(ctx, k, r)
 		},
 	})
 }
+
+// defaultContainer picks the container kubectl would: the one named by the
+// kubectl.kubernetes.io/default-container annotation, else the first.
+func defaultContainer(pod *corev1.Pod) string {
+	if name := pod.Annotations["kubectl.kubernetes.io/default-container"]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+func containerImage(pod *corev1.Pod, name string) string {
+	for _, list := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, c := range list {
+			if c.Name == name {
+				return c.Image
+			}
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	return ""
+}
@@ -3,35 +3,69 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/cached"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// Clients bundles the Kubernetes API clients used by the tools. All clients
+// are interfaces so fake or alternate backends can be plugged in; RestConfig
+// is nil for backends without a live API server.
+//
+// The client fields are never modified after construction. A context switch
+// or kubeconfig reload builds a new bundle that Current returns from then on,
+// so handlers take one snapshot with Current per call and use only that.
 type Clients struct {
-	Logger           *slog.Logger
-	RestConfig       *rest.Config
-	Clientset        *kubernetes.Clientset
-	Dynamic          dynamic.Interface
-	Discovery        discovery.DiscoveryInterface
+	Logger     *slog.Logger
+	RestConfig *rest.Config
+	Clientset  kubernetes.Interface
+	Dynamic    dynamic.Interface
+	Discovery  discovery.DiscoveryInterface
+	// Mapper resolves kinds to resources (discovery-backed for live clusters)
+	Mapper meta.RESTMapper
+	// OpenAPI serves the cluster's OpenAPI v3 documents (built-in kinds and
+	// CRDs); nil when the backend has none
+	OpenAPI          openapi.Client
 	DefaultNamespace string
+	// SnapshotSource is the manifest path when serving from an offline
+	// snapshot (MCP_K8S_SNAPSHOT); such clients are read-only
+	SnapshotSource string
 	// kubeconfig paths (for context switching)
 	kubeconfigPaths []string
+	// contextName is the context selected via SwitchContext; empty means the
+	// kubeconfig's current-context
+	contextName string
+	// mu serializes client rebuilds (context switch, kubeconfig reload)
+	mu sync.Mutex
+	// current is the bundle built by the latest rebuild; nil means c itself
+	current atomic.Pointer[Clients]
+	// handle is the Clients a rebuilt bundle belongs to; nil for the handle
+	handle *Clients
 }
 
 func Load(ctx context.Context, logger *slog.Logger) (*Clients, error) {
+	if snap := os.Getenv("MCP_K8S_SNAPSHOT"); snap != "" {
+		return LoadSnapshot(snap, logger)
+	}
 	// Load order: KUBECONFIG (supports ':'), in-cluster, default
 	var cfg *rest.Config
 	var kcPaths []string
@@ -71,30 +105,82 @@ func Load(ctx context.Context, logger *slog.Logger) (*Clients, error) {
 			}
 		}
 	}
-	cs, err := kubernetes.NewForConfig(cfg)
+	cs, dyn, disc, mapper, err := buildClients(cfg)
 	if err != nil {
 		return nil, err
 	}
-	dyn, err := dynamic.NewForConfig(cfg)
-	if err != nil {
-		return nil, err
+	return &Clients{Logger: logger, RestConfig: cfg, Clientset: cs, Dynamic: dyn, Discovery: disc, Mapper: mapper, OpenAPI: NewOpenAPIClient(disc.OpenAPIV3()), DefaultNamespace: defaultNamespace(), kubeconfigPaths: kcPaths}, nil
+}
+
+// NewForClients builds Clients from existing client implementations, such as
+// client-go fake clientsets in tests. Discovery comes from cs.
+func NewForClients(logger *slog.Logger, cs kubernetes.Interface, dyn dynamic.Interface, mapper meta.RESTMapper) *Clients {
+	return &Clients{Logger: logger, Clientset: cs, Dynamic: dyn, Discovery: cs.Discovery(), Mapper: mapper, DefaultNamespace: defaultNamespace()}
+}
+
+func defaultNamespace() string {
+	if ns := os.Getenv("K8S_NAMESPACE"); ns != "" {
+		return ns
 	}
-	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
-	if err != nil {
-		return nil, err
+	return "default"
+}
+
+// Current returns the clients in use right now. It may be called on the
+// handle or on any snapshot and always returns the latest bundle.
+func (c *Clients) Current() *Clients {
+	h := c.root()
+	if cur := h.current.Load(); cur != nil {
+		return cur
 	}
-	ns := os.Getenv("K8S_NAMESPACE")
-	if ns == "" {
-		ns = "default"
+	return h
+}
+
+// root returns the handle that owns rebuilds and the selected context.
+func (c *Clients) root() *Clients {
+	if c.handle != nil {
+		return c.handle
 	}
-	return &Clients{Logger: logger, RestConfig: cfg, Clientset: cs, Dynamic: dyn, Discovery: disc, DefaultNamespace: ns, kubeconfigPaths: kcPaths}, nil
+	return c
 }
 
 // SwitchContext attempts to switch kube context by name when kubeconfig is present.
 func (c *Clients) SwitchContext(ctx context.Context, contextName string) error {
+	c = c.root()
+	if c.SnapshotSource != "" {
+		return fmt.Errorf("context switching not available (offline snapshot)")
+	}
 	if len(c.kubeconfigPaths) == 0 {
 		return fmt.Errorf("context switching not available (in-cluster)")
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.rebuild(contextName); err != nil {
+		return err
+	}
+	c.contextName = contextName
+	return nil
+}
+
+// Reload rebuilds the clients from the kubeconfig files, keeping the context
+// selected via SwitchContext. On error the previous clients stay in place.
+func (c *Clients) Reload() error {
+	c = c.root()
+	if len(c.kubeconfigPaths) == 0 {
+		return fmt.Errorf("reload not available (in-cluster)")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rebuild(c.contextName)
+}
+
+// KubeconfigPaths returns the kubeconfig files the clients were loaded from.
+func (c *Clients) KubeconfigPaths() []string {
+	return append([]string(nil), c.kubeconfigPaths...)
+}
+
+// rebuild loads the kubeconfig for contextName and publishes a new bundle.
+// Callers must hold c.mu.
+func (c *Clients) rebuild(contextName string) error {
 	rules := &clientcmd.ClientConfigLoadingRules{Precedence: c.kubeconfigPaths}
 	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
 	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
@@ -102,24 +188,49 @@ func (c *Clients) SwitchContext(ctx context.Context, contextName string) error {
 	if err != nil {
 		return err
 	}
-	cs, err := kubernetes.NewForConfig(cfg)
+	cs, dyn, disc, mapper, err := buildClients(cfg)
 	if err != nil {
 		return err
 	}
+	c.current.Store(&Clients{
+		Logger:           c.Logger,
+		RestConfig:       cfg,
+		Clientset:        cs,
+		Dynamic:          dyn,
+		Discovery:        disc,
+		Mapper:           mapper,
+		OpenAPI:          NewOpenAPIClient(disc.OpenAPIV3()),
+		DefaultNamespace: c.DefaultNamespace,
+		SnapshotSource:   c.SnapshotSource,
+		kubeconfigPaths:  c.kubeconfigPaths,
+		handle:           c,
+	})
+	return nil
+}
+
+func buildClients(cfg *rest.Config) (kubernetes.Interface, dynamic.Interface, discovery.DiscoveryInterface, meta.RESTMapper, error) {
+	applyClientTuning(cfg)
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
 	dyn, err := dynamic.NewForConfig(cfg)
 	if err != nil {
-		return err
+		return nil, nil, nil, nil, err
 	}
 	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
-		return err
+		return nil, nil, nil, nil, err
 	}
-	c.RestConfig, c.Clientset, c.Dynamic, c.Discovery = cfg, cs, dyn, disc
-	return nil
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(disc))
+	return cs, dyn, disc, mapper, nil
 }
 
 // ListContexts returns current and list of contexts when kubeconfig is present.
 func (c *Clients) ListContexts() (current string, contexts []struct{ Name, Cluster, User string }, err error) {
+	if c.SnapshotSource != "" {
+		return "", nil, fmt.Errorf("contexts unavailable for offline snapshot")
+	}
 	if len(c.kubeconfigPaths) == 0 {
 		return "", nil, fmt.Errorf("contexts unavailable in in-cluster mode")
 	}
@@ -136,29 +247,77 @@ func (c *Clients) ListContexts() (current string, contexts []struct{ Name, Clust
 }
 
 // PodLogs returns the logs for a pod/container with options.
-func (c *Clients) PodLogs(ctx context.Context, namespace, name, container string, tailLines *int64, sinceSeconds *int64, timestamps *bool) (string, error) {
-	opts := &corev1.PodLogOptions{Container: container, TailLines: tailLines, SinceSeconds: sinceSeconds, Timestamps: false}
-	if timestamps != nil && *timestamps {
-		opts.Timestamps = true
+func (c *Clients) PodLogs(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (string, error) {
+	if c.SnapshotSource != "" {
+		return "", fmt.Errorf("pod logs are not available from an offline snapshot")
 	}
-	req := c.Clientset.CoreV1().Pods(namespace).GetLogs(name, opts)
-	b, err := req.Do(ctx).Raw()
+	b, err := c.Clientset.CoreV1().Pods(namespace).GetLogs(name, opts).Do(ctx).Raw()
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-// Resolve GVK to GVR using discovery and RESTMapper
+// PodLogStream opens a log stream (e.g. with Follow set); the caller closes it.
+func (c *Clients) PodLogStream(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	if c.SnapshotSource != "" {
+		return nil, fmt.Errorf("pod logs are not available from an offline snapshot")
+	}
+	return c.Clientset.CoreV1().Pods(namespace).GetLogs(name, opts).Stream(ctx)
+}
+
+// Resolve GVK to GVR using the RESTMapper. Discovery results are cached, so
+// on a miss (e.g. a CRD installed since) the mapper is reset and asked again.
 func (c *Clients) ResolveResource(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
-	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memoryCached{c.Discovery})
-	m, err := mapper.RESTMapping(schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}, gvk.Version)
+	gk := schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}
+	m, err := c.Mapper.RESTMapping(gk, gvk.Version)
+	if err != nil && meta.IsNoMatchError(err) {
+		if rm, ok := c.Mapper.(meta.ResettableRESTMapper); ok {
+			rm.Reset()
+			m, err = c.Mapper.RESTMapping(gk, gvk.Version)
+		}
+	}
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 	return m.Resource, nil
 }
 
+// ResetOpenAPI drops the cached OpenAPI v3 documents so the next lookup asks
+// the server again, e.g. after a miss for a CRD installed since or an error
+// the cache would otherwise keep returning. Clients not built by
+// NewOpenAPIClient are left alone.
+func (c *Clients) ResetOpenAPI() {
+	if oc, ok := c.OpenAPI.(*openAPIClient); ok {
+		oc.reset()
+	}
+}
+
+// NewOpenAPIClient wraps client with a cache of its documents that
+// Clients.ResetOpenAPI can drop.
+func NewOpenAPIClient(client openapi.Client) openapi.Client {
+	return &openAPIClient{uncached: client, cached: cached.NewClient(client)}
+}
+
+type openAPIClient struct {
+	uncached openapi.Client
+	mu       sync.Mutex
+	cached   openapi.Client
+}
+
+func (o *openAPIClient) Paths() (map[string]openapi.GroupVersion, error) {
+	o.mu.Lock()
+	c := o.cached
+	o.mu.Unlock()
+	return c.Paths()
+}
+
+func (o *openAPIClient) reset() {
+	o.mu.Lock()
+	o.cached = cached.NewClient(o.uncached)
+	o.mu.Unlock()
+}
+
 // YAML to Unstructured helpers
 var decUnstructured = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
 
@@ -167,9 +326,3 @@ func DecodeYAMLToUnstructured(doc []byte) (*unstructured.Unstructured, error) {
 	_, _, err := decUnstructured.Decode(doc, nil, obj)
 	return obj, err
 }
-
-// memoryCached wraps discovery to satisfy RESTMapper cache interface methods used by restmapper.
-type memoryCached struct{ discovery.DiscoveryInterface }
-
-func (m memoryCached) Fresh() bool { return true }
-func (m memoryCached) Invalidate() {}
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	defaultRetryMax  = 3
+	retryBaseDelay   = 200 * time.Millisecond
+	retryMaxDelay    = 5 * time.Second
+	retryAfterCapSec = 30
+)
+
+// ThrottledError is returned when the API server keeps answering 429 after
+// all retries, which usually means API Priority and Fairness is shedding load.
+type ThrottledError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("API server is throttling requests (429 Too Many Requests, likely API Priority and Fairness) after %d attempts; retry later or lower MCP_K8S_QPS: %v", e.Attempts, e.Err)
+}
+
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// applyClientTuning sets client-side rate limits from MCP_K8S_QPS and
+// MCP_K8S_BURST; unset values keep client-go's defaults.
+func applyClientTuning(cfg *rest.Config) {
+	if v, err := strconv.ParseFloat(os.Getenv("MCP_K8S_QPS"), 32); err == nil && v > 0 {
+		cfg.QPS = float32(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("MCP_K8S_BURST")); err == nil && v > 0 {
+		cfg.Burst = v
+	}
+}
+
+// retryMax is the number of retries after the first attempt
+// (MCP_K8S_RETRY_MAX, default 3; 0 disables retries).
+func retryMax() int {
+	n, err := strconv.Atoi(os.Getenv("MCP_K8S_RETRY_MAX"))
+	if err != nil || n < 0 {
+		return defaultRetryMax
+	}
+	return n
+}
+
+// IsRetryable reports whether err is a transient API failure: throttling,
+// 5xx responses, dropped connections or an etcd leader change.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+	var st apierrors.APIStatus
+	if errors.As(err, &st) {
+		code := st.Status().Code
+		return code == 500 || code == 502 || code == 503 || code == 504
+	}
+	if utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) || utilnet.IsHTTP2ConnectionLost(err) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "etcdserver: leader changed") || strings.Contains(msg, "etcdserver: request timed out")
+}
+
+// Retry runs fn until it succeeds, fails with a non-retryable error, or the
+// retry budget is spent. Delays use jittered exponential backoff and honor
+// the server's Retry-After hint. Only use it for reads and idempotent writes.
+func Retry(ctx context.Context, fn func() error) error {
+	max := retryMax()
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt >= max {
+			if apierrors.IsTooManyRequests(err) {
+				return &ThrottledError{Attempts: attempt + 1, Err: err}
+			}
+			return err
+		}
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		if secs, ok := apierrors.SuggestsClientDelay(err); ok && secs > 0 {
+			if secs > retryAfterCapSec {
+				secs = retryAfterCapSec
+			}
+			if ra := time.Duration(secs) * time.Second; ra > wait {
+				wait = ra
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRetry(t *testing.T) {
+	t.Setenv("MCP_K8S_RETRY_MAX", "2")
+	pods := schema.GroupResource{Resource: "pods"}
+
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return apierrors.NewInternalError(errors.New("etcdserver: leader changed"))
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("transient error: err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), func() error {
+		calls++
+		return apierrors.NewNotFound(pods, "x")
+	})
+	if !apierrors.IsNotFound(err) || calls != 1 {
+		t.Fatalf("non-retryable error: err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), func() error {
+		calls++
+		return apierrors.NewTooManyRequests("Too many requests, please try again later.", 0)
+	})
+	var te *ThrottledError
+	if !errors.As(err, &te) || calls != 3 || !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("throttled: err=%v calls=%d", err, calls)
+	}
+}
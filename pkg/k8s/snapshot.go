@@ -0,0 +1,348 @@
+package k8s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// ErrSnapshotReadOnly is returned for every write against a snapshot backend.
+var ErrSnapshotReadOnly = errors.New("offline snapshot is read-only: writes are not supported when serving from MCP_K8S_SNAPSHOT")
+
+// snapshotResource describes how a kind found in a snapshot is served.
+type snapshotResource struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// LoadSnapshot builds Clients that serve reads from exported manifests
+// instead of a live API server. path is a directory (searched recursively),
+// a single manifest, or a .tar/.tar.gz/.tgz archive of YAML or JSON files,
+// such as a support bundle or `kubectl get -A -o yaml` output.
+func LoadSnapshot(path string, logger *slog.Logger) (*Clients, error) {
+	objs, err := readSnapshot(path, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("snapshot %s contains no Kubernetes objects", path)
+	}
+	resources := indexSnapshot(objs)
+
+	// discovery and the RESTMapper only know the kinds present in the snapshot
+	byGV := map[string][]metav1.APIResource{}
+	for gvk, r := range resources {
+		gv := gvk.GroupVersion().String()
+		byGV[gv] = append(byGV[gv], metav1.APIResource{Name: r.gvr.Resource, Kind: gvk.Kind, Namespaced: r.namespaced, Verbs: metav1.Verbs{"get", "list"}})
+	}
+	var lists []*metav1.APIResourceList
+	for gv, rs := range byGV {
+		sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+		lists = append(lists, &metav1.APIResourceList{GroupVersion: gv, APIResources: rs})
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].GroupVersion < lists[j].GroupVersion })
+
+	cs := fake.NewClientset()
+	disc := cs.Discovery().(*fakediscovery.FakeDiscovery)
+	disc.Resources = lists
+	disc.FakedServerVersion = &version.Info{GitVersion: "unknown (offline snapshot)"}
+	groups, err := restmapper.GetAPIGroupResources(disc)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groups)
+
+	dynScheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{}
+	for gvk, r := range resources {
+		dynScheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		dynScheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		listKinds[r.gvr] = gvk.Kind + "List"
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(dynScheme, listKinds)
+
+	for _, u := range objs {
+		r := resources[u.GroupVersionKind()]
+		if err := dyn.Tracker().Create(r.gvr, u, u.GetNamespace()); err != nil {
+			logger.Warn("skipping snapshot object", slog.String("object", objectRef(u)), slog.String("error", err.Error()))
+			continue
+		}
+		// built-in kinds are also served through the typed clientset
+		typed, err := scheme.Scheme.New(u.GroupVersionKind())
+		if err != nil {
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+			logger.Warn("snapshot object not convertible", slog.String("object", objectRef(u)), slog.String("error", err.Error()))
+			continue
+		}
+		_ = cs.Tracker().Add(typed)
+	}
+
+	cs.PrependReactor("*", "*", rejectSnapshotWrites)
+	dyn.PrependReactor("*", "*", rejectSnapshotWrites)
+	cs.PrependReactor("list", "*", fieldSelectorReactor(cs.Tracker()))
+	dyn.PrependReactor("list", "*", fieldSelectorReactor(dyn.Tracker()))
+
+	logger.Info("serving from offline snapshot", slog.String("path", path), slog.Int("objects", len(objs)), slog.Int("kinds", len(resources)))
+	kc := NewForClients(logger, cs, dyn, mapper)
+	kc.SnapshotSource = path
+	return kc, nil
+}
+
+func objectRef(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return u.GetKind() + "/" + u.GetName()
+	}
+	return u.GetKind() + "/" + u.GetNamespace() + "/" + u.GetName()
+}
+
+func rejectSnapshotWrites(action k8stesting.Action) (bool, runtime.Object, error) {
+	switch action.GetVerb() {
+	case "create", "update", "patch", "delete", "delete-collection":
+		return true, nil, ErrSnapshotReadOnly
+	}
+	return false, nil, nil
+}
+
+// fieldSelectorReactor applies list field selectors, which the fake clients
+// ignore, by matching them against the object's fields as dotted paths.
+func fieldSelectorReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		la, ok := action.(k8stesting.ListActionImpl)
+		if !ok {
+			return false, nil, nil
+		}
+		sel := la.GetListRestrictions().Fields
+		if sel == nil || sel.Empty() {
+			return false, nil, nil
+		}
+		list, err := tracker.List(la.GetResource(), la.GetKind(), la.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return true, nil, err
+		}
+		var kept []runtime.Object
+		for _, it := range items {
+			m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(it)
+			if err != nil {
+				continue
+			}
+			set := fields.Set{}
+			flattenFields("", m, set)
+			if sel.Matches(set) {
+				kept = append(kept, it)
+			}
+		}
+		if err := meta.SetList(list, kept); err != nil {
+			return true, nil, err
+		}
+		return true, list, nil
+	}
+}
+
+func flattenFields(prefix string, m map[string]any, out fields.Set) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch vv := v.(type) {
+		case map[string]any:
+			flattenFields(key, vv, out)
+		case []any:
+			// list elements cannot be addressed by field selectors
+		default:
+			out[key] = fmt.Sprint(vv)
+		}
+	}
+}
+
+// indexSnapshot maps every kind in objs to a resource. CRDs in the snapshot
+// provide exact plurals and scope; other kinds use the usual plural guess
+// and are namespaced if their objects carry a namespace.
+func indexSnapshot(objs []*unstructured.Unstructured) map[schema.GroupVersionKind]snapshotResource {
+	out := map[schema.GroupVersionKind]snapshotResource{}
+	for _, u := range objs {
+		if u.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(u.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(u.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(u.Object, "spec", "names", "plural")
+		scope, _, _ := unstructured.NestedString(u.Object, "spec", "scope")
+		versions, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+		for _, v := range versions {
+			vm, _ := v.(map[string]any)
+			name, _ := vm["name"].(string)
+			if name == "" || kind == "" || plural == "" {
+				continue
+			}
+			out[schema.GroupVersionKind{Group: group, Version: name, Kind: kind}] = snapshotResource{
+				gvr:        schema.GroupVersionResource{Group: group, Version: name, Resource: plural},
+				namespaced: scope != "Cluster",
+			}
+		}
+	}
+	for _, u := range objs {
+		gvk := u.GroupVersionKind()
+		r, ok := out[gvk]
+		if !ok {
+			gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+			r = snapshotResource{gvr: gvr}
+		}
+		if u.GetNamespace() != "" {
+			r.namespaced = true
+		}
+		out[gvk] = r
+	}
+	return out
+}
+
+// readSnapshot collects the objects from a directory, archive or file.
+func readSnapshot(path string, logger *slog.Logger) ([]*unstructured.Unstructured, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	var objs []*unstructured.Unstructured
+	add := func(name string, r io.Reader) {
+		got, err := decodeManifests(r)
+		if err != nil {
+			logger.Warn("skipping unreadable snapshot file", slog.String("file", name), slog.String("error", err.Error()))
+		}
+		objs = append(objs, got...)
+	}
+	switch {
+	case fi.IsDir():
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isManifestFile(p) {
+				return err
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			add(p, f)
+			return nil
+		})
+	case isTarball(path):
+		err = readTarball(path, add)
+	default:
+		var f *os.File
+		if f, err = os.Open(path); err == nil {
+			defer f.Close()
+			add(path, f)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return objs, nil
+}
+
+func isManifestFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func isTarball(name string) bool {
+	n := strings.ToLower(name)
+	return strings.HasSuffix(n, ".tar") || strings.HasSuffix(n, ".tar.gz") || strings.HasSuffix(n, ".tgz")
+}
+
+func readTarball(path string, add func(name string, r io.Reader)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if !strings.HasSuffix(strings.ToLower(path), ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag == tar.TypeReg && isManifestFile(h.Name) {
+			add(h.Name, tr)
+		}
+	}
+}
+
+// decodeManifests reads a stream of YAML documents or JSON values, expanding
+// List objects into their items.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	dec := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var out []*unstructured.Unstructured
+	for {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return out, err
+		}
+		if len(m) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: m}
+		if u.IsList() {
+			_ = u.EachListItem(func(o runtime.Object) error {
+				if item, ok := o.(*unstructured.Unstructured); ok && isSnapshotObject(item) {
+					out = append(out, item)
+				}
+				return nil
+			})
+			continue
+		}
+		if isSnapshotObject(u) {
+			out = append(out, u)
+		}
+	}
+}
+
+func isSnapshotObject(u *unstructured.Unstructured) bool {
+	return u.GetAPIVersion() != "" && u.GetKind() != "" && u.GetName() != ""
+}
@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const snapshotPods = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: web-0, namespace: shop}
+  spec: {nodeName: node-a, containers: [{name: app, image: nginx}]}
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: web-1, namespace: shop}
+  spec: {nodeName: node-b, containers: [{name: app, image: nginx}]}
+`
+
+const snapshotCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata: {name: proxies.example.com}
+spec:
+  group: example.com
+  scope: Namespaced
+  names: {kind: Proxy, plural: proxies}
+  versions: [{name: v1, served: true, storage: true}]
+---
+apiVersion: example.com/v1
+kind: Proxy
+metadata: {name: edge, namespace: shop}
+`
+
+func checkSnapshot(t *testing.T, kc *Clients) {
+	t.Helper()
+	ctx := context.Background()
+	pods, err := kc.Clientset.CoreV1().Pods("shop").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=node-b"})
+	if err != nil || len(pods.Items) != 1 || pods.Items[0].Name != "web-1" {
+		t.Fatalf("field-selected pods: %v %v", pods, err)
+	}
+	gvr, err := kc.ResolveResource(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Proxy"})
+	if err != nil || gvr.Resource != "proxies" {
+		t.Fatalf("resolve CR: %v %v", gvr, err)
+	}
+	if _, err := kc.Dynamic.Resource(gvr).Namespace("shop").Get(ctx, "edge", metav1.GetOptions{}); err != nil {
+		t.Fatalf("get CR: %v", err)
+	}
+	_, err = kc.Clientset.CoreV1().ConfigMaps("shop").Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "x"}}, metav1.CreateOptions{})
+	if !errors.Is(err, ErrSnapshotReadOnly) {
+		t.Fatalf("write not rejected: %v", err)
+	}
+	if _, err := kc.PodLogs(ctx, "shop", "web-0", &corev1.PodLogOptions{}); err == nil {
+		t.Fatalf("expected logs to be unavailable")
+	}
+}
+
+func TestLoadSnapshotDir(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "shop"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "shop", "pods.yaml"), []byte(snapshotPods), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "crds.yml"), []byte(snapshotCRD), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a manifest"), 0o600)
+	t.Setenv("MCP_K8S_SNAPSHOT", dir)
+	kc, err := Load(context.Background(), slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSnapshot(t, kc)
+}
+
+func TestLoadSnapshotTarball(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range map[string]string{"bundle/pods.yaml": snapshotPods, "bundle/crds.yaml": snapshotCRD} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(body)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(body))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	path := filepath.Join(t.TempDir(), "bundle.tgz")
+	_ = os.WriteFile(path, buf.Bytes(), 0o600)
+	kc, err := LoadSnapshot(path, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSnapshot(t, kc)
+}
@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultKubeconfigPoll = 2 * time.Second
+	loadRetryMin          = time.Second
+	loadRetryMax          = time.Minute
+)
+
+// KubeconfigPollInterval returns how often kubeconfig files are checked for
+// changes (MCP_K8S_KUBECONFIG_POLL_MS, default 2s; 0 disables watching).
+func KubeconfigPollInterval() time.Duration {
+	v := os.Getenv("MCP_K8S_KUBECONFIG_POLL_MS")
+	if v == "" {
+		return defaultKubeconfigPoll
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return defaultKubeconfigPoll
+	}
+	return time.Duration(n) * time.Millisecond
+}
+
+// LoadWithRetry calls Load until it succeeds, backing off exponentially
+// between attempts. It only fails when ctx is done.
+func LoadWithRetry(ctx context.Context, logger *slog.Logger) (*Clients, error) {
+	delay := loadRetryMin
+	for {
+		kc, err := Load(ctx, logger)
+		if err == nil {
+			return kc, nil
+		}
+		logger.Warn("k8s not initialized, retrying", slog.String("error", err.Error()), slog.Duration("retryIn", delay))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > loadRetryMax {
+			delay = loadRetryMax
+		}
+	}
+}
+
+// fileState is what we compare between polls; a missing file is recorded
+// too so that creating or removing a kubeconfig counts as a change.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFiles(paths []string) map[string]fileState {
+	out := make(map[string]fileState, len(paths))
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			out[p] = fileState{}
+			continue
+		}
+		out[p] = fileState{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+	}
+	return out
+}
+
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, sa := range a {
+		sb, ok := b[p]
+		if !ok || sa.exists != sb.exists || sa.size != sb.size || !sa.modTime.Equal(sb.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchKubeconfig polls the kubeconfig files every interval and calls Reload
+// when any of them changes; onReload receives the result of each reload.
+// It returns when ctx is done, immediately for in-cluster clients.
+func (c *Clients) WatchKubeconfig(ctx context.Context, interval time.Duration, onReload func(error)) {
+	paths := c.KubeconfigPaths()
+	if len(paths) == 0 || interval <= 0 {
+		return
+	}
+	last := statFiles(paths)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		cur := statFiles(paths)
+		if sameFiles(last, cur) {
+			continue
+		}
+		last = cur
+		err := c.Reload()
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: %s
+contexts:
+- name: ctx
+  context:
+    cluster: c
+    user: u
+current-context: ctx
+users:
+- name: u
+  user:
+    token: t
+`
+
+func writeKubeconfig(t *testing.T, path, server string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(testKubeconfig, server)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchKubeconfigReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	writeKubeconfig(t, path, "https://one.example:6443")
+	t.Setenv("KUBECONFIG", path)
+	kc, err := Load(context.Background(), slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	go kc.WatchKubeconfig(ctx, 10*time.Millisecond, func(err error) { reloaded <- err })
+	// let the watcher take its initial snapshot
+	time.Sleep(50 * time.Millisecond)
+
+	writeKubeconfig(t, path, "https://two.example:6443")
+	// bump mtime in case the filesystem clock granularity hides the write
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(path, future, future)
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after kubeconfig change")
+	}
+	if host := kc.Current().RestConfig.Host; host != "https://two.example:6443" {
+		t.Fatalf("host not reloaded: %s", host)
+	}
+	// the handle itself is never modified, so snapshots taken before stay usable
+	if kc.RestConfig.Host != "https://one.example:6443" {
+		t.Fatalf("handle modified: %s", kc.RestConfig.Host)
+	}
+}
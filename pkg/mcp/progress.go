@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type progressKey struct{}
+
+// progressReporter sends notifications/progress for one tools/call.
+type progressReporter struct {
+	s     *Server
+	token json.RawMessage
+}
+
+// withProgress attaches a progress reporter to ctx; token is empty when the
+// client sent none with the call.
+func (s *Server) withProgress(ctx context.Context, token []byte) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressReporter{s: s, token: json.RawMessage(token)})
+}
+
+// ReportProgress notifies the client about a long-running tool call. total
+// may be 0 when unknown. It is a no-op unless the client asked for progress.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	r, _ := ctx.Value(progressKey{}).(*progressReporter)
+	if r == nil || len(r.token) == 0 {
+		return
+	}
+	params := map[string]any{"progressToken": r.token, "progress": progress}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	_ = r.s.Notify("notifications/progress", params)
+}
+
+// ReportLine sends one line of streamed output (e.g. a followed log) while
+// the call runs: as progress when the client asked for it, else as an info
+// log message. It is a no-op outside a tools/call.
+func ReportLine(ctx context.Context, progress, total float64, line string) {
+	r, _ := ctx.Value(progressKey{}).(*progressReporter)
+	if r == nil {
+		return
+	}
+	if len(r.token) == 0 {
+		_ = r.s.LogMessage("info", line)
+		return
+	}
+	ReportProgress(ctx, progress, total, line)
+}
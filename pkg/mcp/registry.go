@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 )
 
 // Registry stores tools and exposes MCP built-ins
 type Registry struct {
+	// tools are re-registered from background goroutines (k8s init, reload)
+	mu    sync.RWMutex
 	tools map[string]*Tool
 }
 
@@ -15,11 +18,33 @@ func NewRegistry() *Registry { return &Registry{tools: map[string]*Tool{}} }
 
 func (r *Registry) Register(t Tool) {
 	tt := t // copy
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.tools[t.Name] = &tt
 	// Only register the primary tool name, no dotted aliases
 }
 
+// Get returns a copy of the named tool.
+func (r *Registry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	if !ok {
+		return Tool{}, false
+	}
+	return *t, true
+}
+
+// Remove unregisters the named tool.
+func (r *Registry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, name)
+}
+
 func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	out := make([]Tool, 0, len(r.tools))
 	for _, t := range r.tools {
 		out = append(out, Tool{Name: t.Name, Description: t.Description, InputSchema: t.InputSchema})
@@ -29,7 +54,9 @@ func (r *Registry) List() []Tool {
 
 func (r *Registry) Call(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
 	// Look for exact match only
+	r.mu.RLock()
 	t, ok := r.tools[name]
+	r.mu.RUnlock()
 	if !ok {
 		return ToolsCallResult{Content: []TextContent{{Type: "text", Text: fmt.Sprintf("tool %s not found", name)}}, IsError: true}, nil
 	}
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"sync"
 )
@@ -22,6 +23,13 @@ type rpcResponse struct {
 	Error   *rpcError       `json:"error,omitempty"`
 }
 
+// rpcNotification is a server-initiated JSON-RPC message without an id.
+type rpcNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 type rpcError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -37,8 +45,55 @@ type Server struct {
 	// onInitialized is called once, after a successful initialize response is sent
 	onInitialized func(ctx context.Context, s *Server)
 	initOnce      sync.Once
+	// notify writes a message to the active transport; nil until Run starts
+	notify func(v any) error
+	// calls tracks running tools/call requests
+	calls callSet
+}
+
+// errCancelledByClient is the cancel cause of a call the client cancelled.
+var errCancelledByClient = errors.New("cancelled by client")
+
+// runningCall is a tools/call in progress.
+type runningCall struct {
+	cancel context.CancelCauseFunc
+}
+
+// callSet holds the running tools/call requests by JSON-RPC id.
+type callSet struct {
+	mu   sync.Mutex
+	byID map[string]*runningCall
+	wg   sync.WaitGroup
+}
+
+func (cs *callSet) add(id string, c *runningCall) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.byID == nil {
+		cs.byID = make(map[string]*runningCall)
+	}
+	cs.byID[id] = c
+	cs.wg.Add(1)
+}
+
+func (cs *callSet) get(id string) *runningCall {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.byID[id]
+}
+
+func (cs *callSet) done(id string, c *runningCall) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.byID[id] == c {
+		delete(cs.byID, id)
+	}
+	cs.wg.Done()
 }
 
+// wait blocks until every running call has finished.
+func (cs *callSet) wait() { cs.wg.Wait() }
+
 type Handler func(ctx context.Context, params json.RawMessage) (any, *rpcError)
 
 func NewServer(logger *slog.Logger) *Server {
@@ -77,3 +132,40 @@ func (s *Server) triggerInitialized(ctx context.Context) {
 		}
 	})
 }
+
+// serverCapabilities is advertised in the initialize response.
+func serverCapabilities() map[string]any {
+	return map[string]any{
+		"tools":   map[string]any{"listChanged": true},
+		"logging": map[string]any{},
+	}
+}
+
+func (s *Server) setNotifier(f func(v any) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = f
+}
+
+// Notify sends a JSON-RPC notification to the client. It fails when the
+// transport loop is not running.
+func (s *Server) Notify(method string, params any) error {
+	s.mu.RLock()
+	send := s.notify
+	s.mu.RUnlock()
+	if send == nil {
+		return errors.New("transport not running")
+	}
+	return send(rpcNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// NotifyToolsListChanged tells the client to fetch tools/list again.
+func (s *Server) NotifyToolsListChanged() error {
+	return s.Notify("notifications/tools/list_changed", nil)
+}
+
+// LogMessage sends an MCP log notification (level is one of debug, info,
+// notice, warning, error).
+func (s *Server) LogMessage(level string, data any) error {
+	return s.Notify("notifications/message", map[string]any{"level": level, "logger": "mcp-k8s-server", "data": data})
+}
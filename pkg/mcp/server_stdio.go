@@ -29,6 +29,10 @@ func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
 func (s *Server) runFramed(ctx context.Context, r io.Reader, w io.Writer) error {
 	fr := newFramedReader(r)
 	fw := newFramedWriter(w)
+	s.setNotifier(fw.WriteJSON)
+	defer s.setNotifier(nil)
+	// let running tool calls finish before the transport goes away
+	defer s.calls.wait()
 
 	reg := s.Registry()
 	s.installBuiltins(reg)
@@ -69,7 +73,7 @@ func (s *Server) runFramed(ctx context.Context, r io.Reader, w io.Writer) error
 			}
 			res := InitializeResult{
 				ServerInfo:   ServerInfo{Name: "mcp-k8s-server", Version: "0.1.0-go"},
-				Capabilities: map[string]any{"tools": map[string]any{}},
+				Capabilities: serverCapabilities(),
 			}
 			if err := fw.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res}); err != nil {
 				return err
@@ -86,25 +90,9 @@ func (s *Server) runFramed(ctx context.Context, r io.Reader, w io.Writer) error
 				_ = fw.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "invalid params"}})
 				continue
 			}
-			// Per-call timeout to mirror TS behavior when applicable
-			timeoutMs := 0
-			if tm := getEnvInt("MCP_K8S_TIMEOUT_MS", 0); tm > 0 {
-				timeoutMs = tm
-			}
-			callCtx := ctx
-			var cancel context.CancelFunc
-			if timeoutMs > 0 {
-				callCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
-				defer cancel()
-			}
-			out, err := reg.Call(callCtx, p.Name, p.Arguments)
-			if err != nil {
-				_ = fw.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32000, Message: err.Error()}})
-				continue
-			}
-
-			// Always ToolsCallResult now
-			_ = fw.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: out})
+			s.startCall(ctx, reg, req.ID, p, fw.WriteJSON)
+		case "notifications/cancelled":
+			s.cancelCall(req.Params)
 		default:
 			_ = fw.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "Method not found"}})
 		}
@@ -114,8 +102,10 @@ func (s *Server) runFramed(ctx context.Context, r io.Reader, w io.Writer) error
 // runNDJSON supports newline-delimited JSON for simple CLI testing
 func (s *Server) runNDJSON(ctx context.Context, r io.Reader, w io.Writer) error {
 	dec := json.NewDecoder(r)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(false)
+	enc := newNDJSONWriter(w)
+	s.setNotifier(enc.WriteJSON)
+	defer s.setNotifier(nil)
+	defer s.calls.wait()
 
 	reg := s.Registry()
 	s.installBuiltins(reg)
@@ -131,11 +121,11 @@ func (s *Server) runNDJSON(ctx context.Context, r io.Reader, w io.Writer) error
 			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
 				return nil
 			}
-			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "parse error"}})
+			_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "parse error"}})
 			continue
 		}
 		if req.JSONRPC != "2.0" {
-			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32600, Message: "invalid request"}})
+			_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32600, Message: "invalid request"}})
 			continue
 		}
 		switch req.Method {
@@ -143,44 +133,77 @@ func (s *Server) runNDJSON(ctx context.Context, r io.Reader, w io.Writer) error
 			var p InitializeParams
 			if len(req.Params) > 0 {
 				if err := json.Unmarshal(req.Params, &p); err != nil {
-					_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "invalid params"}})
+					_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "invalid params"}})
 					continue
 				}
 			}
-			res := InitializeResult{ServerInfo: ServerInfo{Name: "mcp-k8s-server", Version: "0.1.0-go"}, Capabilities: map[string]any{"tools": map[string]any{}}}
-			if err := enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res}); err != nil {
+			res := InitializeResult{ServerInfo: ServerInfo{Name: "mcp-k8s-server", Version: "0.1.0-go"}, Capabilities: serverCapabilities()}
+			if err := enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res}); err != nil {
 				return err
 			}
 			s.triggerInitialized(ctx)
 		case "tools/list":
 			res := ToolsListResult{Tools: reg.List()}
-			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res})
+			_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res})
 		case "tools/call":
 			var p ToolsCallParams
 			if err := json.Unmarshal(req.Params, &p); err != nil {
-				_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "invalid params"}})
+				_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "invalid params"}})
 				continue
 			}
-			timeoutMs := 0
-			if tm := getEnvInt("MCP_K8S_TIMEOUT_MS", 0); tm > 0 {
-				timeoutMs = tm
-			}
-			callCtx := ctx
-			var cancel context.CancelFunc
-			if timeoutMs > 0 {
-				callCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
-				defer cancel()
-			}
-			out, err := reg.Call(callCtx, p.Name, p.Arguments)
-			if err != nil {
-				_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32000, Message: err.Error()}})
-				continue
-			}
-			// Always ToolsCallResult now
-			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: out})
+			s.startCall(ctx, reg, req.ID, p, enc.WriteJSON)
+		case "notifications/cancelled":
+			s.cancelCall(req.Params)
 		default:
-			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "Method not found"}})
+			_ = enc.WriteJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "Method not found"}})
+		}
+	}
+}
+
+// startCall runs a tools/call in its own goroutine so a long call (e.g. a log
+// follow) does not hold up other requests, and writes the response when it
+// is done. A notifications/cancelled for id cancels the call's context; the
+// response is then dropped, as MCP asks.
+func (s *Server) startCall(ctx context.Context, reg *Registry, id json.RawMessage, p ToolsCallParams, write func(v any) error) {
+	callCtx, cancel := context.WithCancelCause(s.withProgress(ctx, p.Meta.ProgressToken))
+	// Per-call timeout to mirror TS behavior when applicable
+	stop := func() {}
+	if tm := getEnvInt("MCP_K8S_TIMEOUT_MS", 0); tm > 0 {
+		callCtx, stop = context.WithTimeout(callCtx, time.Duration(tm)*time.Millisecond)
+	}
+	key := string(id)
+	c := &runningCall{cancel: cancel}
+	s.calls.add(key, c)
+	go func() {
+		defer s.calls.done(key, c)
+		defer cancel(nil)
+		defer stop()
+		out, err := reg.Call(callCtx, p.Name, p.Arguments)
+		if errors.Is(context.Cause(callCtx), errCancelledByClient) {
+			return
+		}
+		if err != nil {
+			_ = write(rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: -32000, Message: err.Error()}})
+			return
 		}
+		// Always ToolsCallResult now
+		_ = write(rpcResponse{JSONRPC: "2.0", ID: id, Result: out})
+	}()
+}
+
+// cancelCall handles notifications/cancelled. Unknown or finished request
+// ids are ignored.
+func (s *Server) cancelCall(params json.RawMessage) {
+	var p struct {
+		RequestID json.RawMessage `json:"requestId"`
+		Reason    string          `json:"reason,omitempty"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || len(p.RequestID) == 0 {
+		return
+	}
+	if c := s.calls.get(string(p.RequestID)); c != nil {
+		s.logger.Debug("tool call cancelled", slog.String("id", string(p.RequestID)), slog.String("reason", p.Reason))
+		c.cancel(errCancelledByClient)
 	}
 }
 
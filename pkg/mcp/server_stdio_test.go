@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -80,3 +81,104 @@ func readFrame(b []byte) ([]byte, []byte) {
 	}
 	return b[start : start+n], b[start+n:]
 }
+
+func TestNotifyNDJSON(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	srv := NewServer(logger)
+	if err := srv.NotifyToolsListChanged(); err == nil {
+		t.Fatalf("expected error before transport runs")
+	}
+	srv.Registry().Register(Tool{Name: "reload", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return "ok", srv.LogMessage("info", "reloaded")
+	}})
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"reload"}}` + "\n")
+	var out bytes.Buffer
+	if err := srv.Run(context.Background(), in, &out); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", out.String())
+	}
+	var n map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &n); err != nil {
+		t.Fatalf("bad notification: %v", err)
+	}
+	if n["method"] != "notifications/message" || n["id"] != nil {
+		t.Fatalf("bad notification: %v", n)
+	}
+	if err := srv.NotifyToolsListChanged(); err == nil {
+		t.Fatalf("expected error after transport stopped")
+	}
+}
+
+func TestProgressNotifications(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	srv := NewServer(logger)
+	srv.Registry().Register(Tool{Name: "slow", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+		ReportProgress(ctx, 1, 2, "half way")
+		return "done", nil
+	}})
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"slow","_meta":{"progressToken":"tok-1"}}}` + "\n" +
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"slow"}}` + "\n")
+	var out bytes.Buffer
+	if err := srv.Run(context.Background(), in, &out); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	// progress for the first call only, plus both results; calls run
+	// concurrently, so the order of the results is not fixed
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	progress := 0
+	for _, l := range lines {
+		if strings.Contains(l, `"method":"notifications/progress"`) {
+			progress++
+			if !strings.Contains(l, `"progressToken":"tok-1"`) {
+				t.Fatalf("progress for the wrong call: %s", l)
+			}
+		}
+	}
+	if len(lines) != 3 || progress != 1 {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestCancelledCall(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	srv := NewServer(logger)
+	started := make(chan struct{})
+	srv.Registry().Register(Tool{Name: "block", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	send := func(msg string) {
+		if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := bufio.NewScanner(outR)
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	// a blocked call does not hold up the next one
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)
+	if !lines.Scan() || !strings.Contains(lines.Text(), `"id":2`) {
+		t.Fatalf("expected the echo result first, got %q", lines.Text())
+	}
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"user"}}`)
+	inW.Close()
+	// Run waits for the cancelled call, which sends no response
+	for lines.Scan() {
+		t.Fatalf("unexpected output after cancel: %s", lines.Text())
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+}
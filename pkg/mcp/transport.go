@@ -8,6 +8,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // LSP-style header framed transport: `Content-Length: N\r\n\r\n<JSON>`
@@ -54,7 +55,8 @@ func (fr *framedReader) ReadMessage() ([]byte, error) {
 }
 
 type framedWriter struct {
-	w *bufio.Writer
+	mu sync.Mutex
+	w  *bufio.Writer
 }
 
 func newFramedWriter(w io.Writer) *framedWriter { return &framedWriter{w: bufio.NewWriter(w)} }
@@ -64,6 +66,8 @@ func (fw *framedWriter) WriteJSON(v any) error {
 	if err != nil {
 		return err
 	}
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
 	var out bytes.Buffer
 	fmt.Fprintf(&out, "Content-Length: %d\r\n\r\n", len(b))
 	out.Write(b)
@@ -72,3 +76,22 @@ func (fw *framedWriter) WriteJSON(v any) error {
 	}
 	return fw.w.Flush()
 }
+
+// ndjsonWriter writes one JSON value per line. Like framedWriter it is safe
+// for concurrent use so notifications can be sent from background goroutines.
+type ndjsonWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &ndjsonWriter{enc: enc}
+}
+
+func (nw *ndjsonWriter) WriteJSON(v any) error {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	return nw.enc.Encode(v)
+}
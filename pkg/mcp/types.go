@@ -44,6 +44,10 @@ type ToolsListResult struct {
 type ToolsCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      struct {
+		// ProgressToken is set by clients that want notifications/progress
+		ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+	} `json:"_meta,omitempty"`
 }
 
 type TextContent struct {